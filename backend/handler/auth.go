@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/pirosiki197/event_reminder/problem"
+)
+
+// forwardedUserHeader は認証プロキシが付与するtraQのユーザー名のヘッダー
+// クライアントが自由に付けられるため、信頼するプロキシから届いた場合のみ使う
+const forwardedUserHeader = "X-Forwarded-User"
+
+// ParseTrustedProxies はX-Forwarded-Userを付与する認証プロキシのアドレスを解析する
+// カンマ区切りのIPアドレスまたはCIDRを受け付け、未指定の場合はどこからのヘッダーも信用しない
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.Contains(v, "/") {
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// fromTrustedProxy はリクエストが信頼する認証プロキシから直接届いたかを返す
+func (h *Handler) fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range h.trustedProxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestUser はリクエストしたtraQユーザーの名前を返す
+// 認証プロキシを通っていない場合は401を返してfalseを返す
+func (h *Handler) requestUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user := r.Header.Get(forwardedUserHeader)
+	if user != "" && !h.fromTrustedProxy(r) {
+		// 認証プロキシを迂回して直接ヘッダーを付けたリクエストは他人になりすませるため受け付けない
+		h.logger.Warn("ignored "+forwardedUserHeader+" from untrusted address", slog.String("remote_addr", r.RemoteAddr))
+		user = ""
+	}
+	if user == "" {
+		problem.Write(w, r, problem.New(http.StatusUnauthorized, forwardedUserHeader+" header from a trusted proxy is required"))
+		return "", false
+	}
+	return user, true
+}
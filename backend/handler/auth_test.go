@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	if proxies, err := ParseTrustedProxies(""); err != nil || len(proxies) != 0 {
+		t.Errorf("ParseTrustedProxies(\"\") = %v, %v, want none", proxies, err)
+	}
+	if _, err := ParseTrustedProxies("10.0.0.1, not-an-ip"); err == nil {
+		t.Error("ParseTrustedProxies accepted an invalid address")
+	}
+}
+
+func TestRequestUser(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.1, 172.16.0.0/12, ::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{logger: slog.New(slog.DiscardHandler), trustedProxies: proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		user       string
+		wantOK     bool
+	}{
+		{name: "trusted address", remoteAddr: "10.0.0.1:5000", user: "alice", wantOK: true},
+		{name: "trusted range", remoteAddr: "172.18.0.5:5000", user: "alice", wantOK: true},
+		{name: "trusted ipv6", remoteAddr: "[::1]:5000", user: "alice", wantOK: true},
+		{name: "untrusted address", remoteAddr: "10.0.0.2:5000", user: "alice", wantOK: false},
+		{name: "missing header", remoteAddr: "10.0.0.1:5000", user: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/holding-tasks/1/comments", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.user != "" {
+				r.Header.Set(forwardedUserHeader, tt.user)
+			}
+			w := httptest.NewRecorder()
+
+			user, ok := h.requestUser(w, r)
+			if ok != tt.wantOK {
+				t.Fatalf("requestUser() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && user != tt.user {
+				t.Errorf("requestUser() = %q, want %q", user, tt.user)
+			}
+			if !ok && w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", w.Code)
+			}
+		})
+	}
+}
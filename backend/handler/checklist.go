@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// チェックリスト用のリクエスト/レスポンス型
+
+type CreateChecklistItemRequest struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+func (req CreateChecklistItemRequest) Validate() error {
+	if req.Text == "" {
+		return &services.ValidationError{Field: "text", Message: "checklist item text is required"}
+	}
+	return nil
+}
+
+type UpdateChecklistItemRequest struct {
+	Text *string `json:"text,omitempty"`
+	Done *bool   `json:"done,omitempty"`
+	// 0始まりの並び順。範囲外の場合は先頭または末尾に移動する
+	Position *int `json:"position,omitempty"`
+}
+
+type ChecklistItemResponse struct {
+	ID       string     `json:"id"`
+	TaskID   string     `json:"taskId"`
+	Position int        `json:"position"`
+	Text     string     `json:"text"`
+	Done     bool       `json:"done"`
+	DoneAt   *time.Time `json:"doneAt,omitempty"`
+}
+
+func newChecklistItemResponse(item models.ChecklistItem) ChecklistItemResponse {
+	return ChecklistItemResponse{
+		ID:       strconv.Itoa(item.ID),
+		TaskID:   strconv.Itoa(item.TaskID),
+		Position: item.Position,
+		Text:     item.Text,
+		Done:     item.Done(),
+		DoneAt:   item.DoneAt,
+	}
+}
+
+// GET /api/v1/holding-tasks/{taskId}/checklist
+// タスクのチェックリストを並び順で取得
+func (h *Handler) GetChecklistItems(w http.ResponseWriter, r *http.Request) {
+	taskIDStr := r.PathValue("taskId")
+	taskID, err := strconv.Atoi(taskIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid task_id")
+		return
+	}
+
+	items, err := h.taskSvc.GetChecklistItems(r.Context(), taskID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := make([]ChecklistItemResponse, len(items))
+	for i, item := range items {
+		response[i] = newChecklistItemResponse(item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /api/v1/holding-tasks/{taskId}/checklist
+// タスクのチェックリストの末尾に項目を追加
+func (h *Handler) CreateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	taskIDStr := r.PathValue("taskId")
+	taskID, err := strconv.Atoi(taskIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid task_id")
+		return
+	}
+
+	var req CreateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	item := models.ChecklistItem{
+		TaskID: taskID,
+		Text:   req.Text,
+	}
+	if req.Done {
+		now := time.Now()
+		item.DoneAt = &now
+	}
+
+	itemID, err := h.taskSvc.CreateChecklistItem(r.Context(), item)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// 末尾の位置はサービスで決まるので取得し直す
+	item, err = h.taskSvc.GetChecklistItemByID(r.Context(), itemID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := newChecklistItemResponse(item)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// PATCH /api/v1/checklist-items/{itemId}
+// チェックリストの項目を部分更新（完了状態の切り替えや並び替え）
+func (h *Handler) UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	itemIDStr := r.PathValue("itemId")
+	itemID, err := strconv.Atoi(itemIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid item_id")
+		return
+	}
+
+	var req UpdateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+
+	// 既存の項目を取得
+	existingItem, err := h.taskSvc.GetChecklistItemByID(r.Context(), itemID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// 部分更新の適用
+	updatedItem := existingItem
+
+	if req.Text != nil {
+		updatedItem.Text = *req.Text
+	}
+	if req.Position != nil {
+		updatedItem.Position = *req.Position
+	}
+	if req.Done != nil && *req.Done != existingItem.Done() {
+		if *req.Done {
+			now := time.Now()
+			updatedItem.DoneAt = &now
+		} else {
+			updatedItem.DoneAt = nil
+		}
+	}
+
+	if err := h.taskSvc.UpdateChecklistItem(r.Context(), itemID, updatedItem); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	updatedItem, err = h.taskSvc.GetChecklistItemByID(r.Context(), itemID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := newChecklistItemResponse(updatedItem)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DELETE /api/v1/checklist-items/{itemId}
+// チェックリストの項目を削除
+func (h *Handler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	itemIDStr := r.PathValue("itemId")
+	itemID, err := strconv.Atoi(itemIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid item_id")
+		return
+	}
+
+	if err := h.taskSvc.DeleteChecklistItem(r.Context(), itemID); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// コメント用のリクエスト/レスポンス型
+
+type CreateCommentRequest struct {
+	Body string `json:"body"`
+	// trueで開催のチャンネルにもコメントを投稿する
+	Notify bool `json:"notify"`
+}
+
+func (req CreateCommentRequest) Validate() error {
+	if req.Body == "" {
+		return &services.ValidationError{Field: "body", Message: "comment body is required"}
+	}
+	return nil
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type CommentResponse struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"taskId"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Edited    bool      `json:"edited"`
+	// チャンネルへの投稿を指定した場合のみ、投稿できたかを返す
+	Notified *bool `json:"notified,omitempty"`
+}
+
+func newCommentResponse(comment models.TaskComment) CommentResponse {
+	return CommentResponse{
+		ID:        strconv.Itoa(comment.ID),
+		TaskID:    strconv.Itoa(comment.TaskID),
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+		Edited:    comment.Edited(),
+	}
+}
+
+// GET /api/v1/holding-tasks/{taskId}/comments
+// タスクへのコメントを古い順に取得
+func (h *Handler) GetComments(w http.ResponseWriter, r *http.Request) {
+	taskIDStr := r.PathValue("taskId")
+	taskID, err := strconv.Atoi(taskIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid task_id")
+		return
+	}
+
+	comments, err := h.taskSvc.GetComments(r.Context(), taskID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := make([]CommentResponse, len(comments))
+	for i, comment := range comments {
+		response[i] = newCommentResponse(comment)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /api/v1/holding-tasks/{taskId}/comments
+// タスクにコメントを追加（notifyを指定すると開催のチャンネルにも投稿）
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	taskIDStr := r.PathValue("taskId")
+	taskID, err := strconv.Atoi(taskIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid task_id")
+		return
+	}
+
+	// コメントした人は認証プロキシが付与したユーザーに限る
+	author, ok := h.requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	commentID, err := h.taskSvc.CreateComment(r.Context(), models.TaskComment{
+		TaskID: taskID,
+		Author: author,
+		Body:   req.Body,
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// 作成日時はDBで決まるので取得し直す
+	comment, err := h.taskSvc.GetCommentByID(r.Context(), commentID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := newCommentResponse(comment)
+
+	if req.Notify {
+		// コメント自体は保存できているので、投稿に失敗しても201を返す
+		notified := true
+		if err := h.postComment(r, comment); err != nil {
+			h.logger.Error("failed to post comment to traQ", slog.String("err", err.Error()))
+			notified = false
+		}
+		response.Notified = &notified
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// postComment はコメントをタスクの開催のチャンネルに投稿する
+func (h *Handler) postComment(r *http.Request, comment models.TaskComment) error {
+	task, err := h.taskSvc.GetTaskByID(r.Context(), comment.TaskID)
+	if err != nil {
+		return err
+	}
+	holding, err := h.taskSvc.GetHoldingByID(r.Context(), task.HoldingID)
+	if err != nil {
+		return err
+	}
+	// 本文はそのまま転記するとボットの名前でメンションできてしまうため、埋め込みを無効にして引用する
+	content := fmt.Sprintf("%sさんが「%s」にコメントしました\n%s", comment.Author, task.Name, quote(comment.Body))
+	return h.traqSvc.PostPlainMessage(r.Context(), holding.ChannelID, content)
+}
+
+// quote は本文を行ごとに引用の形式にする
+func quote(body string) string {
+	return "> " + strings.ReplaceAll(body, "\n", "\n> ")
+}
+
+// PATCH /api/v1/comments/{commentId}
+// コメントの本文を編集（コメントした本人のみ）
+func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	commentIDStr := r.PathValue("commentId")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid comment_id")
+		return
+	}
+
+	actor, ok := h.requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+
+	if err := h.taskSvc.UpdateComment(r.Context(), commentID, actor, req.Body); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	comment, err := h.taskSvc.GetCommentByID(r.Context(), commentID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := newCommentResponse(comment)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DELETE /api/v1/comments/{commentId}
+// コメントを削除（コメントした本人のみ）
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	commentIDStr := r.PathValue("commentId")
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid comment_id")
+		return
+	}
+
+	actor, ok := h.requestUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.taskSvc.DeleteComment(r.Context(), commentID, actor); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// Deadline用のレスポンス型
+
+type DeadlineResponse struct {
+	HoldingTaskResponse
+	HoldingName    string `json:"holdingName"`
+	HoldingDate    string `json:"holdingDate"`
+	HoldingEndDate string `json:"holdingEndDate,omitempty"`
+	ChannelID      string `json:"channelId"`
+	EventID        string `json:"eventId"`
+	EventName      string `json:"eventName"`
+	RemindDate     string `json:"remindDate"`
+	DaysRemaining  int    `json:"daysRemaining"`
+	Reminded       bool   `json:"reminded"`
+	Overdue        bool   `json:"overdue"`
+}
+
+type DeadlineWeekResponse struct {
+	WeekStart string             `json:"weekStart"`
+	WeekEnd   string             `json:"weekEnd"`
+	Deadlines []DeadlineResponse `json:"deadlines"`
+}
+
+func newDeadlineResponse(deadline services.Deadline) DeadlineResponse {
+	return DeadlineResponse{
+		HoldingTaskResponse: newHoldingTaskResponse(deadline.Task),
+		HoldingName:         deadline.Holding.Name,
+		HoldingDate:         deadline.Holding.Date.Format(time.DateOnly),
+		HoldingEndDate:      formatOptionalDate(deadline.Holding.EndDate),
+		ChannelID:           deadline.Holding.ChannelID,
+		EventID:             strconv.Itoa(deadline.Holding.EventID),
+		EventName:           deadline.EventName,
+		RemindDate:          deadline.RemindDate.Format(time.DateOnly),
+		DaysRemaining:       deadline.DaysRemaining,
+		Reminded:            deadline.Task.Status == models.TaskStatusSent,
+		Overdue:             deadline.Overdue,
+	}
+}
+
+// GET /api/v1/deadlines
+// 開催をまたいでタスクの締め切り（リマインド日）を週ごとにまとめて取得
+func (h *Handler) GetDeadlines(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var filter services.DeadlineFilter
+	var err error
+	if filter.EventID, err = parseOptionalInt(q, "event_id"); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if filter.From, err = parseOptionalDate(q, "from"); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if filter.To, err = parseOptionalDate(q, "to"); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	filter.ChannelID = q.Get("channel_id")
+	filter.When = q.Get("when")
+
+	weeks, err := h.taskSvc.ListDeadlines(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// レスポンス変換
+	response := make([]DeadlineWeekResponse, len(weeks))
+	for i, week := range weeks {
+		deadlines := make([]DeadlineResponse, len(week.Deadlines))
+		for j, deadline := range week.Deadlines {
+			deadlines[j] = newDeadlineResponse(deadline)
+		}
+		response[i] = DeadlineWeekResponse{
+			WeekStart: week.WeekStart.Format(time.DateOnly),
+			WeekEnd:   week.WeekStart.AddDate(0, 0, 6).Format(time.DateOnly),
+			Deadlines: deadlines,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
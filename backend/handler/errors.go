@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/pirosiki197/event_reminder/problem"
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// writeError はサービス層のエラーを対応するステータスコードのproblem+jsonで返す
+// 想定外のエラーは内容をログにだけ残し、クライアントには500を返す
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *services.ValidationError
+	var batchErr *services.BatchValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		p := problem.New(http.StatusBadRequest, validationErr.Error())
+		if validationErr.Field != "" {
+			p.InvalidParams = []problem.InvalidParam{{Name: validationErr.Field, Reason: validationErr.Message}}
+		}
+		problem.Write(w, r, p)
+	case errors.As(err, &batchErr):
+		p := problem.New(http.StatusBadRequest, batchErr.Error())
+		for _, e := range batchErr.Errors {
+			p.InvalidParams = append(p.InvalidParams, problem.InvalidParam{Name: e.Field, Reason: e.Message})
+		}
+		problem.Write(w, r, p)
+	case errors.Is(err, services.ErrNotFound):
+		problem.Write(w, r, problem.New(http.StatusNotFound, err.Error()))
+	case errors.Is(err, services.ErrConflict):
+		problem.Write(w, r, problem.New(http.StatusConflict, err.Error()))
+	case errors.Is(err, services.ErrForbidden):
+		problem.Write(w, r, problem.New(http.StatusForbidden, err.Error()))
+	case errors.Is(err, services.ErrPreconditionFailed):
+		problem.Write(w, r, problem.New(http.StatusPreconditionFailed, err.Error()))
+	default:
+		h.logger.Error("internal server error",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("err", err.Error()),
+		)
+		problem.Write(w, r, problem.New(http.StatusInternalServerError, ""))
+	}
+}
+
+// writeBadRequest はパスパラメータやボディが読めない場合の400を返す
+func writeBadRequest(w http.ResponseWriter, r *http.Request, detail string) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, detail))
+}
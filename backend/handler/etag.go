@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// 楽観的排他制御（ETagとIf-Match）
+// ETagはリソースの版をそのまま使う（例: "3"）
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseIfMatch はIf-Matchヘッダーで指定された版を返す
+// ヘッダーが無い場合や"*"の場合は0を返し、版を確かめない
+func parseIfMatch(r *http.Request) (int, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(v, `"`))
+	if err != nil || version <= 0 || !strings.HasPrefix(v, `"`) || !strings.HasSuffix(v, `"`) {
+		// 弱いETagや複数の指定はどの版とも一致しないものとして扱う
+		return 0, fmt.Errorf("If-Match %s does not match any version: %w", v, services.ErrPreconditionFailed)
+	}
+	return version, nil
+}
+
+// implicitConflict はIf-Matchを指定していない更新が、読み込んでから書き込むまでの間に
+// 他の更新と競合した場合のエラーを409にする（412はクライアントが前提条件を指定した場合に限る）
+func implicitConflict(err error, resource string, id int) error {
+	if errors.Is(err, services.ErrPreconditionFailed) {
+		return fmt.Errorf("%s %d was modified by another request, reload and retry: %w", resource, id, services.ErrConflict)
+	}
+	return err
+}
@@ -9,34 +9,52 @@ import (
 )
 
 type CreateEventRequest struct {
-	Name string `json:"name"`
+	Name          string               `json:"name"`
+	CatchUpPolicy models.CatchUpPolicy `json:"catchUpPolicy"`
+	BlockedPolicy models.BlockedPolicy `json:"blockedPolicy"`
 }
 
 func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	var req CreateEventRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid request body")
 		return
 	}
 
+	if req.CatchUpPolicy == "" {
+		req.CatchUpPolicy = models.CatchUpPolicyAll
+	}
+	if req.BlockedPolicy == "" {
+		req.BlockedPolicy = models.BlockedPolicyNotify
+	}
+
 	event := models.Event{
-		Name: req.Name,
+		Name:          req.Name,
+		CatchUpPolicy: req.CatchUpPolicy,
+		BlockedPolicy: req.BlockedPolicy,
 	}
-	id, err := h.taskSvc.CreateEvent(event)
+	id, err := h.taskSvc.CreateEvent(r.Context(), event)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 	event.ID = id
+	event.Version = models.InitialVersion
 
 	jsonEncoded(w, event)
 }
 
 func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
-	events, err := h.taskSvc.GetAllEvents()
+	includeArchived, err := parseOptionalBool(r.URL.Query(), "include_archived")
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	events, err := h.taskSvc.GetAllEvents(r.Context(), includeArchived)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 	jsonEncoded(w, events)
@@ -45,56 +63,118 @@ func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetEvent(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.PathValue("eventId"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid event_id")
 		return
 	}
 
-	event, err := h.taskSvc.GetEventByID(id)
+	event, err := h.taskSvc.GetEventByID(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
+	setETag(w, event.Version)
 	jsonEncoded(w, event)
 }
 
 type UpdateEventRequest struct {
-	Name string `json:"name"`
+	Name          string               `json:"name"`
+	CatchUpPolicy models.CatchUpPolicy `json:"catchUpPolicy"`
+	BlockedPolicy models.BlockedPolicy `json:"blockedPolicy"`
 }
 
 func (h *Handler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	var req UpdateEventRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid request body")
 		return
 	}
 	id, err := strconv.Atoi(r.PathValue("eventId"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid event_id")
+		return
+	}
+	version, err := parseIfMatch(r)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	err = h.taskSvc.UpdateEvent(id, models.Event{
-		Name: req.Name,
+	// If-Matchが無い場合も読み込んだ時点の版で確かめ、書き込むまでに他の更新が入っていれば上書きしない
+	ifMatch := version != 0
+	if !ifMatch {
+		existingEvent, err := h.taskSvc.GetEventByID(r.Context(), id)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		version = existingEvent.Version
+	}
+
+	event, err := h.taskSvc.UpdateEvent(r.Context(), id, models.Event{
+		Name:          req.Name,
+		CatchUpPolicy: req.CatchUpPolicy,
+		BlockedPolicy: req.BlockedPolicy,
+		Version:       version,
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		if !ifMatch {
+			err = implicitConflict(err, "event", id)
+		}
+		h.writeError(w, r, err)
 		return
 	}
+
+	setETag(w, event.Version)
+	jsonEncoded(w, event)
 }
 
 func (h *Handler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.PathValue("eventId"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid event_id")
 		return
 	}
 
-	if err = h.taskSvc.DeleteEvent(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err = h.taskSvc.DeleteEvent(r.Context(), id); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) ArchiveEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("eventId"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid event_id")
+		return
+	}
+
+	event, err := h.taskSvc.ArchiveEvent(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	setETag(w, event.Version)
+	jsonEncoded(w, event)
+}
+
+func (h *Handler) UnarchiveEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("eventId"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid event_id")
+		return
+	}
+
+	event, err := h.taskSvc.UnarchiveEvent(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	setETag(w, event.Version)
+	jsonEncoded(w, event)
+}
@@ -3,36 +3,67 @@ package handler
 import (
 	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/pirosiki197/event_reminder/metrics"
+	"github.com/pirosiki197/event_reminder/openapi"
 	"github.com/pirosiki197/event_reminder/services"
+	"github.com/pirosiki197/event_reminder/tracing"
 	slogchi "github.com/samber/slog-chi"
 )
 
 type Handler struct {
-	taskSvc *services.TaskService
-	traqSvc *services.TraQService
-	logger  *slog.Logger
+	taskSvc   *services.TaskService
+	traqSvc   *services.TraQService
+	remindSvc *services.RemindService
+	logger    *slog.Logger
+	// X-Forwarded-Userを信用する認証プロキシのアドレス
+	trustedProxies []netip.Prefix
 }
 
-func New(taskSvc *services.TaskService, traqSvc *services.TraQService, logger *slog.Logger) *Handler {
+func New(taskSvc *services.TaskService, traqSvc *services.TraQService, remindSvc *services.RemindService, logger *slog.Logger, trustedProxies []netip.Prefix) *Handler {
 	return &Handler{
-		taskSvc: taskSvc,
-		traqSvc: traqSvc,
-		logger:  logger,
+		taskSvc:        taskSvc,
+		traqSvc:        traqSvc,
+		remindSvc:      remindSvc,
+		logger:         logger,
+		trustedProxies: trustedProxies,
 	}
 }
 
-func (h *Handler) SetupRoutes(router chi.Router) {
+// SetupRoutes はルーティングを設定する
+// 埋め込みのAPI仕様が読めない場合はビルドの誤りなのでエラーを返す
+func (h *Handler) SetupRoutes(router chi.Router) error {
+	router.Use(metrics.Middleware)
+
+	// Metrics
+	router.Handle("/metrics", metrics.Handler())
+
+	// Health check
+	router.Get("/healthz", h.Healthz)
+	router.Get("/readyz", h.Readyz)
+
+	validator, err := openapi.New()
+	if err != nil {
+		return fmt.Errorf("load openapi spec: %w", err)
+	}
+
 	api := chi.NewRouter()
 	router.Mount("/api/v1", api)
 
+	api.Use(tracing.Middleware)
 	api.Use(slogchi.New(h.logger))
 	api.Use(middleware.Recoverer)
 	api.Use(middleware.Compress(gzip.BestSpeed))
+	api.Use(validator.Middleware)
+
+	// OpenAPI
+	api.Get("/openapi.json", validator.ServeSpec)
 
 	// Events (イベントマスター)
 	api.Post("/events", h.CreateEvent)
@@ -40,6 +71,9 @@ func (h *Handler) SetupRoutes(router chi.Router) {
 	api.Get("/events/{eventId}", h.GetEvent)
 	api.Put("/events/{eventId}", h.UpdateEvent)
 	api.Delete("/events/{eventId}", h.DeleteEvent)
+	api.Post("/events/{eventId}/restore", h.RestoreEvent)
+	api.Post("/events/{eventId}/archive", h.ArchiveEvent)
+	api.Post("/events/{eventId}/unarchive", h.UnarchiveEvent)
 
 	// Holdings (開催)
 	api.Post("/holdings", h.CreateHolding)
@@ -47,15 +81,43 @@ func (h *Handler) SetupRoutes(router chi.Router) {
 	api.Get("/holdings/{holdingId}", h.GetHolding)
 	api.Patch("/holdings/{holdingId}", h.UpdateHolding)
 	api.Delete("/holdings/{holdingId}", h.DeleteHolding)
+	api.Post("/holdings/{holdingId}/clone", h.CloneHolding)
+	api.Post("/holdings/{holdingId}/restore", h.RestoreHolding)
 
 	// HoldingTasks (開催タスク - 開催に紐づく)
 	api.Get("/holdings/{holdingId}/tasks", h.GetHoldingTasks)
 	api.Post("/holdings/{holdingId}/tasks", h.CreateHoldingTask)
+	api.Patch("/holdings/{holdingId}/tasks", h.BatchHoldingTasks)
+	api.Get("/tasks", h.GetTasks)
+	api.Get("/deadlines", h.GetDeadlines)
+	api.Get("/holding-tasks/{taskId}", h.GetHoldingTask)
 	api.Patch("/holding-tasks/{taskId}", h.UpdateHoldingTask)
 	api.Delete("/holding-tasks/{taskId}", h.DeleteHoldingTask)
+	api.Post("/holding-tasks/{taskId}/restore", h.RestoreHoldingTask)
+
+	// ChecklistItems (チェックリスト - 開催タスクに紐づく)
+	api.Get("/holding-tasks/{taskId}/checklist", h.GetChecklistItems)
+	api.Post("/holding-tasks/{taskId}/checklist", h.CreateChecklistItem)
+	api.Patch("/checklist-items/{itemId}", h.UpdateChecklistItem)
+	api.Delete("/checklist-items/{itemId}", h.DeleteChecklistItem)
+
+	// Comments (コメント - 開催タスクに紐づく)
+	api.Get("/holding-tasks/{taskId}/comments", h.GetComments)
+	api.Post("/holding-tasks/{taskId}/comments", h.CreateComment)
+	api.Patch("/comments/{commentId}", h.UpdateComment)
+	api.Delete("/comments/{commentId}", h.DeleteComment)
+
+	// Trash (ゴミ箱)
+	api.Get("/trash", h.GetTrash)
 
 	// traQ channel
 	api.Get("/channels", h.GetChannelList)
+
+	// traQ user / user group (メンションの指定用)
+	api.Get("/users", h.GetUserList)
+	api.Get("/user-groups", h.GetUserGroupList)
+
+	return nil
 }
 
 func jsonEncoded(w http.ResponseWriter, obj any) {
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 依存先ごとのチェックのタイムアウト
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status    string     `json:"status"`
+	LatencyMs int64      `json:"latencyMs"`
+	Error     string     `json:"error,omitempty"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+}
+
+// GET /healthz
+// プロセスが応答できるかだけを返す
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	jsonEncoded(w, HealthResponse{Status: "ok"})
+}
+
+// GET /readyz
+// DB・traQ API・スケジューラの状態を確認し、全て正常な場合のみ200を返す
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]func(ctx context.Context) (CheckResult, error){
+		"db": func(ctx context.Context) (CheckResult, error) {
+			return CheckResult{}, h.taskSvc.Ping(ctx)
+		},
+		"traq": func(ctx context.Context) (CheckResult, error) {
+			return CheckResult{}, h.traqSvc.Ping(ctx)
+		},
+		"scheduler": func(ctx context.Context) (CheckResult, error) {
+			var res CheckResult
+			lastRunAt, err := h.remindSvc.CheckHealth(ctx)
+			if !lastRunAt.IsZero() {
+				res.LastRunAt = &lastRunAt
+			}
+			return res, err
+		},
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	response := HealthResponse{
+		Status: "ok",
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			res, err := check(ctx)
+			res.LatencyMs = time.Since(start).Milliseconds()
+			res.Status = "ok"
+			if err != nil {
+				res.Status = "error"
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[name] = res
+			if err != nil {
+				response.Status = "error"
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
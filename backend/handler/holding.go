@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
 )
 
 // Holding用のリクエスト/レスポンス型
@@ -15,118 +20,268 @@ import (
 type CreateHoldingRequest struct {
 	Name      string `json:"name"`
 	Date      string `json:"date"`
+	StartTime string `json:"startTime"`
+	// 複数日にわたる開催の最終日
+	EndDate   string `json:"endDate"`
 	ChannelID string `json:"channelId"`
-	Mention   string `json:"mention"`
-	EventID   string `json:"eventId"`
+	// メンションするtraQのユーザー・グループ
+	Mentions []models.HoldingMention `json:"mentions"`
+	// 旧形式の自由記述のメンション（廃止予定）。mentionsを省略した場合のみmentionsに読み替える
+	Mention *string `json:"mention"`
+	EventID string  `json:"eventId"`
+}
+
+// legacyMentionDeprecation はmentionを廃止予定にした日時（RFC 9745のDeprecationヘッダーの値）
+const legacyMentionDeprecation = "@1792368000"
+
+// resolveLegacyMention は旧形式の自由記述のメンションをユーザー・グループのメンションに読み替える
+// 既存のクライアントのために次のリリースまでは受け付け、Deprecationヘッダーで廃止予定を知らせる
+// mentionsも指定された場合はmentionを無視する。traQに無い名前を含む場合は400を返す
+func (h *Handler) resolveLegacyMention(w http.ResponseWriter, r *http.Request, mentions []models.HoldingMention, mention *string) ([]models.HoldingMention, error) {
+	if mention == nil {
+		return mentions, nil
+	}
+	w.Header().Set("Deprecation", legacyMentionDeprecation)
+	if mentions != nil || strings.TrimSpace(*mention) == "" {
+		return mentions, nil
+	}
+	resolved, ok, err := h.traqSvc.ResolveLegacyMention(r.Context(), *mention)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &services.ValidationError{Field: "mention", Message: "contains unknown users or groups, use mentions instead"}
+	}
+	return resolved, nil
 }
 
 func (req CreateHoldingRequest) Validate() error {
 	if req.Name == "" {
-		return errors.New("holding name is required")
+		return &services.ValidationError{Field: "name", Message: "holding name is required"}
 	}
 	if req.Date == "" {
-		return errors.New("holding date is required")
+		return &services.ValidationError{Field: "date", Message: "holding date is required"}
 	}
 	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
-		return errors.New("holding date must be in YYYY-MM-DD format")
+		return &services.ValidationError{Field: "date", Message: "holding date must be in YYYY-MM-DD format"}
 	}
 	if req.ChannelID == "" {
-		return errors.New("channel_id is required")
+		return &services.ValidationError{Field: "channelId", Message: "channel_id is required"}
 	}
-	if req.Mention == "" {
-		return errors.New("mention is required")
+	if len(req.Mentions) == 0 {
+		return &services.ValidationError{Field: "mentions", Message: "mentions is required"}
 	}
 	if req.EventID == "" {
-		return errors.New("event id is required")
+		return &services.ValidationError{Field: "eventId", Message: "event id is required"}
 	}
 	return nil
 }
 
 type UpdateHoldingRequest struct {
-	Name      *string `json:"name,omitempty"`
-	Date      *string `json:"date,omitempty"`
+	Name *string `json:"name,omitempty"`
+	Date *string `json:"date,omitempty"`
+	// 空文字列で開始時刻を解除する
+	StartTime *string `json:"startTime,omitempty"`
+	// 空文字列で1日のみの開催に戻す
+	EndDate   *string `json:"endDate,omitempty"`
 	ChannelID *string `json:"channelId,omitempty"`
-	Mention   *string `json:"mention,omitempty"`
+	// 省略した場合はメンションを変更しない
+	Mentions []models.HoldingMention `json:"mentions,omitempty"`
+	// 旧形式の自由記述のメンション（廃止予定）。mentionsを省略した場合のみmentionsに読み替える
+	Mention *string `json:"mention,omitempty"`
 }
 
 type HoldingResponse struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	Date      string `json:"date"`
+	StartTime string `json:"startTime,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
 	ChannelID string `json:"channelId"`
-	Mention   string `json:"mention"`
-	EventID   string `json:"eventId,omitempty"`
+	// 定期的な確認で分かったチャンネルの状態（ok以外なら通知先を変える必要がある）
+	ChannelStatus    models.ChannelStatus    `json:"channelStatus"`
+	ChannelCheckedAt *time.Time              `json:"channelCheckedAt,omitempty"`
+	Mentions         []models.HoldingMention `json:"mentions"`
+	Mention          string                  `json:"mention"`
+	EventID          string                  `json:"eventId,omitempty"`
+	Version          int                     `json:"version"`
+
+	// includeで指定された場合のみ返す
+	Event *models.Event         `json:"event,omitempty"`
+	Tasks []HoldingTaskResponse `json:"tasks,omitzero"`
 }
 
-// GET /api/v1/holdings
-// 全ての開催を取得（クエリパラメータでフィルタ可能）
-func (h *Handler) GetHoldings(w http.ResponseWriter, r *http.Request) {
-	sourceEventID := r.URL.Query().Get("source_event_id")
+func newHoldingResponse(holding models.Holding) HoldingResponse {
+	if holding.Mentions == nil {
+		holding.Mentions = []models.HoldingMention{}
+	}
+	return HoldingResponse{
+		ID:               strconv.Itoa(holding.ID),
+		Name:             holding.Name,
+		Date:             holding.Date.Format(time.DateOnly),
+		StartTime:        formatTimeOfDay(holding.StartTime),
+		EndDate:          formatOptionalDate(holding.EndDate),
+		ChannelID:        holding.ChannelID,
+		ChannelStatus:    holding.ChannelStatus,
+		ChannelCheckedAt: holding.ChannelCheckedAt,
+		Mentions:         holding.Mentions,
+		Mention:          holding.Mention,
+		EventID:          strconv.Itoa(holding.EventID),
+		Version:          holding.Version,
+	}
+}
 
-	var holdings []models.Holding
-	var err error
+func newHoldingResponseWithRelations(holding services.HoldingWithRelations) HoldingResponse {
+	response := newHoldingResponse(holding.Holding)
+	response.Event = holding.Event
+	if holding.Tasks != nil {
+		response.Tasks = make([]HoldingTaskResponse, len(holding.Tasks))
+		for i, task := range holding.Tasks {
+			response.Tasks[i] = newHoldingTaskResponse(task)
+		}
+	}
+	return response
+}
 
-	if sourceEventID != "" {
-		eventID, parseErr := strconv.Atoi(sourceEventID)
-		if parseErr != nil {
-			http.Error(w, "invalid source_event_id", http.StatusBadRequest)
-			return
+// parseHoldingInclude は include=event,tasks の形式で指定された関連リソースを解析する
+func parseHoldingInclude(q url.Values) (services.HoldingInclude, error) {
+	var include services.HoldingInclude
+	for _, v := range q["include"] {
+		for name := range strings.SplitSeq(v, ",") {
+			switch strings.TrimSpace(name) {
+			case "":
+			case "event":
+				include.Event = true
+			case "tasks":
+				include.Tasks = true
+			default:
+				return include, &services.ValidationError{Field: "include", Message: fmt.Sprintf("unknown relation %q", name)}
+			}
 		}
-		holdings, err = h.taskSvc.GetHoldingsByEventID(eventID)
-	} else {
-		holdings, err = h.taskSvc.GetAllHoldings()
 	}
+	return include, nil
+}
+
+// validateChannel は通知先チャンネルが存在し、アーカイブされていないかを確かめる
+// traQに繋がらない場合は保存を妨げず、定期的な確認に任せる
+func (h *Handler) validateChannel(ctx context.Context, channelID string) error {
+	status, err := h.traqSvc.GetChannelStatus(ctx, channelID)
+	if err != nil {
+		h.logger.Warn("failed to validate channel", slog.String("channel_id", channelID), slog.String("err", err.Error()))
+		return nil
+	}
+	switch status {
+	case models.ChannelStatusArchived:
+		return &services.ValidationError{Field: "channelId", Message: "channel is archived"}
+	case models.ChannelStatusNotFound:
+		return &services.ValidationError{Field: "channelId", Message: "channel does not exist"}
+	}
+	return nil
+}
 
+// validateMentions はメンションするユーザー・グループがtraQに存在するかを確かめる
+// traQに繋がらない場合は保存を妨げない（存在しないものは送信時に飛ばされる）
+func (h *Handler) validateMentions(ctx context.Context, mentions []models.HoldingMention) error {
+	unknown, err := h.traqSvc.FindUnknownMentions(ctx, mentions)
 	if err != nil {
-		h.logger.Error("failed to get holdings", "error", err)
-		http.Error(w, "failed to get holdings", http.StatusInternalServerError)
+		h.logger.Warn("failed to validate mentions", slog.String("err", err.Error()))
+		return nil
+	}
+	var batchErr services.BatchValidationError
+	for _, i := range unknown {
+		batchErr.Add(fmt.Sprintf("mentions.%d", i), &services.ValidationError{Field: "id", Message: "user or group does not exist"})
+	}
+	return batchErr.Err()
+}
+
+// GET /api/v1/holdings
+// 開催一覧を取得（クエリパラメータで絞り込み・並び替え・ページネーションが可能）
+func (h *Handler) GetHoldings(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseHoldingFilter(r.URL.Query())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	page, err := h.taskSvc.ListHoldings(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	// レスポンス変換
-	response := make([]HoldingResponse, len(holdings))
-	for i, holding := range holdings {
-		response[i] = HoldingResponse{
-			ID:        strconv.Itoa(holding.ID),
-			Name:      holding.Name,
-			Date:      holding.Date.Format(time.DateOnly),
-			ChannelID: holding.ChannelID,
-			Mention:   holding.Mention,
-			EventID:   strconv.Itoa(holding.EventID),
-		}
+	response := make([]HoldingResponse, len(page.Items))
+	for i, holding := range page.Items {
+		response[i] = newHoldingResponseWithRelations(holding)
 	}
 
+	setNextCursor(w, page.NextCursor)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
+func parseHoldingFilter(q url.Values) (services.HoldingFilter, error) {
+	var filter services.HoldingFilter
+	var err error
+
+	// source_event_id は event_id の旧名
+	eventIDParam := "event_id"
+	if !q.Has(eventIDParam) {
+		eventIDParam = "source_event_id"
+	}
+	if filter.EventID, err = parseOptionalInt(q, eventIDParam); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseOptionalDate(q, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseOptionalDate(q, "to"); err != nil {
+		return filter, err
+	}
+	if filter.ListOptions, err = parseListOptions(q); err != nil {
+		return filter, err
+	}
+	if filter.Include, err = parseHoldingInclude(q); err != nil {
+		return filter, err
+	}
+	if filter.IncludeArchived, err = parseOptionalBool(q, "include_archived"); err != nil {
+		return filter, err
+	}
+	filter.ChannelStatus = models.ChannelStatus(q.Get("channel_status"))
+	if filter.ChannelStatus != "" && !filter.ChannelStatus.Valid() {
+		return filter, &services.ValidationError{Field: "channel_status", Message: "unknown channel status"}
+	}
+	filter.ChannelID = q.Get("channel_id")
+	filter.When = q.Get("when")
+	return filter, nil
+}
+
 // GET /api/v1/holdings/{holdingId}
-// 特定の開催を取得
+// 特定の開催を取得（includeでイベントとタスクを埋め込める）
 func (h *Handler) GetHolding(w http.ResponseWriter, r *http.Request) {
 	holdingIDStr := r.PathValue("holdingId")
 	holdingID, err := strconv.Atoi(holdingIDStr)
 	if err != nil {
-		http.Error(w, "invalid holding_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid holding_id")
 		return
 	}
 
-	holding, err := h.taskSvc.GetHoldingByID(holdingID)
+	include, err := parseHoldingInclude(r.URL.Query())
 	if err != nil {
-		h.logger.Error("failed to get holding", "error", err)
-		http.Error(w, "holding not found", http.StatusNotFound)
+		h.writeError(w, r, err)
 		return
 	}
 
-	response := HoldingResponse{
-		ID:        strconv.Itoa(holding.ID),
-		Name:      holding.Name,
-		Date:      holding.Date.Format(time.DateOnly),
-		ChannelID: holding.ChannelID,
-		Mention:   holding.Mention,
-		EventID:   strconv.Itoa(holding.EventID),
+	holding, err := h.taskSvc.GetHoldingWithRelations(r.Context(), holdingID, include)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
+	response := newHoldingResponseWithRelations(holding)
+
+	setETag(w, holding.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -137,43 +292,62 @@ func (h *Handler) GetHolding(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) CreateHolding(w http.ResponseWriter, r *http.Request) {
 	var req CreateHoldingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid request body")
 		return
 	}
 
+	var err error
+	if req.Mentions, err = h.resolveLegacyMention(w, r, req.Mentions, req.Mention); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
 	if err := req.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.validateChannel(r.Context(), req.ChannelID); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if err := h.validateMentions(r.Context(), req.Mentions); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	holdingDate, _ := time.Parse("2006-01-02", req.Date)
 	eventID, _ := strconv.Atoi(req.EventID)
+	startTime, err := parseOptionalTimeOfDay("startTime", req.StartTime)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	endDate, err := parseOptionalDateField("endDate", req.EndDate)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
 
 	holding := models.Holding{
 		EventID:   eventID,
 		Name:      req.Name,
 		Date:      holdingDate,
+		StartTime: startTime,
+		EndDate:   endDate,
 		ChannelID: req.ChannelID,
-		Mention:   req.Mention,
+		Mentions:  req.Mentions,
 	}
 
-	holdingID, err := h.taskSvc.CreateHolding(holding)
+	holdingID, err := h.taskSvc.CreateHolding(r.Context(), holding)
 	if err != nil {
-		h.logger.Error("failed to create holding", "error", err)
-		http.Error(w, "failed to create holding", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
 	holding.ID = holdingID
+	holding.ChannelStatus = models.ChannelStatusOK
+	holding.Version = models.InitialVersion
 
-	response := HoldingResponse{
-		ID:        strconv.Itoa(holding.ID),
-		Name:      holding.Name,
-		Date:      holding.Date.Format(time.DateOnly),
-		ChannelID: holding.ChannelID,
-		Mention:   holding.Mention,
-		EventID:   strconv.Itoa(holding.EventID),
-	}
+	response := newHoldingResponse(holding)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -186,33 +360,35 @@ func (h *Handler) UpdateHolding(w http.ResponseWriter, r *http.Request) {
 	holdingIDStr := r.PathValue("holdingId")
 	holdingID, err := strconv.Atoi(holdingIDStr)
 	if err != nil {
-		http.Error(w, "invalid holding_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid holding_id")
 		return
 	}
 
 	var req UpdateHoldingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+	if req.Mentions, err = h.resolveLegacyMention(w, r, req.Mentions, req.Mention); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	// 既存の開催を取得
-	existingHolding, err := h.taskSvc.GetHoldingByID(holdingID)
+	existingHolding, err := h.taskSvc.GetHoldingByID(r.Context(), holdingID)
 	if err != nil {
-		h.logger.Error("failed to get holding", "error", err)
-		http.Error(w, "holding not found", http.StatusNotFound)
+		h.writeError(w, r, err)
 		return
 	}
 
 	// 部分更新の適用
-	updatedHolding := models.Holding{
-		ID:        existingHolding.ID,
-		EventID:   existingHolding.EventID,
-		Name:      existingHolding.Name,
-		Date:      existingHolding.Date,
-		ChannelID: existingHolding.ChannelID,
-		Mention:   existingHolding.Mention,
-	}
+	updatedHolding := existingHolding
 
 	if req.Name != nil {
 		updatedHolding.Name = *req.Name
@@ -220,49 +396,190 @@ func (h *Handler) UpdateHolding(w http.ResponseWriter, r *http.Request) {
 	if req.Date != nil {
 		updatedHolding.Date, err = time.Parse(time.DateOnly, *req.Date)
 		if err != nil {
-			http.Error(w, "invalid format of holding date", http.StatusBadRequest)
+			writeBadRequest(w, r, "invalid format of holding date")
+			return
 		}
 	}
-	if req.ChannelID != nil {
+	if req.StartTime != nil {
+		updatedHolding.StartTime, err = parseOptionalTimeOfDay("startTime", *req.StartTime)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+	if req.EndDate != nil {
+		updatedHolding.EndDate, err = parseOptionalDateField("endDate", *req.EndDate)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+	if req.ChannelID != nil && *req.ChannelID != existingHolding.ChannelID {
+		if err := h.validateChannel(r.Context(), *req.ChannelID); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
 		updatedHolding.ChannelID = *req.ChannelID
+		updatedHolding.ChannelStatus = models.ChannelStatusOK
+		updatedHolding.ChannelCheckedAt = nil
 	}
-	if req.Mention != nil {
-		updatedHolding.Mention = *req.Mention
+	if req.Mentions != nil {
+		if err := h.validateMentions(r.Context(), req.Mentions); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		updatedHolding.Mentions = req.Mentions
+		// メンションを指定し直したら旧形式のメンションは使わなくなる
+		updatedHolding.Mention = ""
 	}
 
-	if err := h.taskSvc.UpdateHolding(holdingID, updatedHolding); err != nil {
-		h.logger.Error("failed to update holding", "error", err)
-		http.Error(w, "failed to update holding", http.StatusInternalServerError)
-		return
+	// If-Matchが無い場合も読み込んだ時点の版で確かめ、書き込むまでに他の更新が入っていれば上書きしない
+	ifMatch := version != 0
+	if !ifMatch {
+		version = existingHolding.Version
 	}
+	updatedHolding.Version = version
 
-	response := HoldingResponse{
-		ID:        strconv.Itoa(updatedHolding.ID),
-		Name:      updatedHolding.Name,
-		Date:      updatedHolding.Date.Format(time.DateOnly),
-		ChannelID: updatedHolding.ChannelID,
-		Mention:   updatedHolding.Mention,
-		EventID:   strconv.Itoa(updatedHolding.EventID),
+	if err := h.taskSvc.UpdateHolding(r.Context(), holdingID, updatedHolding); err != nil {
+		if !ifMatch {
+			err = implicitConflict(err, "holding", holdingID)
+		}
+		h.writeError(w, r, err)
+		return
 	}
+	updatedHolding.Version++
+
+	response := newHoldingResponse(updatedHolding)
 
+	setETag(w, updatedHolding.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
+type CloneHoldingRequest struct {
+	// 複製後の開催日（必須）
+	Date string `json:"date"`
+	// 以下は省略した場合は複製元の値を引き継ぐ
+	Name      *string `json:"name,omitempty"`
+	StartTime *string `json:"startTime,omitempty"`
+	// 省略した場合は複製元と同じ日数の開催にする
+	EndDate   *string                 `json:"endDate,omitempty"`
+	EventID   *string                 `json:"eventId,omitempty"`
+	ChannelID *string                 `json:"channelId,omitempty"`
+	Mentions  []models.HoldingMention `json:"mentions,omitempty"`
+	// 旧形式の自由記述のメンション（廃止予定）。mentionsを省略した場合のみmentionsに読み替える
+	Mention *string `json:"mention,omitempty"`
+}
+
+// POST /api/v1/holdings/{holdingId}/clone
+// 開催をタスクごと複製（送信状態と完了状態はリセット）
+func (h *Handler) CloneHolding(w http.ResponseWriter, r *http.Request) {
+	holdingIDStr := r.PathValue("holdingId")
+	holdingID, err := strconv.Atoi(holdingIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid holding_id")
+		return
+	}
+
+	var req CloneHoldingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+	if req.Mentions, err = h.resolveLegacyMention(w, r, req.Mentions, req.Mention); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	source, err := h.taskSvc.GetHoldingByID(r.Context(), holdingID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	holding := source
+	holding.Date, err = time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		h.writeError(w, r, &services.ValidationError{Field: "date", Message: "holding date must be in YYYY-MM-DD format"})
+		return
+	}
+	if source.EndDate != nil {
+		days := source.EndDate.Sub(source.Date).Round(24*time.Hour) / (24 * time.Hour)
+		endDate := holding.Date.AddDate(0, 0, int(days))
+		holding.EndDate = &endDate
+	}
+
+	if req.Name != nil {
+		holding.Name = *req.Name
+	}
+	if req.StartTime != nil {
+		holding.StartTime, err = parseOptionalTimeOfDay("startTime", *req.StartTime)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+	if req.EndDate != nil {
+		holding.EndDate, err = parseOptionalDateField("endDate", *req.EndDate)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+	if req.EventID != nil {
+		holding.EventID, err = strconv.Atoi(*req.EventID)
+		if err != nil {
+			h.writeError(w, r, &services.ValidationError{Field: "eventId", Message: "invalid event id"})
+			return
+		}
+	}
+	if req.ChannelID != nil {
+		holding.ChannelID = *req.ChannelID
+	}
+	if req.Mentions != nil {
+		if err := h.validateMentions(r.Context(), req.Mentions); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		holding.Mentions = req.Mentions
+		holding.Mention = ""
+	}
+
+	// 複製元のチャンネルを引き継ぐ場合も、その後アーカイブされていないかを確かめる
+	if err := h.validateChannel(r.Context(), holding.ChannelID); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	holding.ID, err = h.taskSvc.CloneHolding(r.Context(), holdingID, holding)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	holding.ChannelStatus = models.ChannelStatusOK
+	holding.ChannelCheckedAt = nil
+	holding.Version = models.InitialVersion
+
+	response := newHoldingResponse(holding)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
 // DELETE /api/v1/holdings/{holdingId}
-// 開催を削除（関連するHoldingTasksも削除）
+// 開催を関連するHoldingTasksごとゴミ箱に入れる
 func (h *Handler) DeleteHolding(w http.ResponseWriter, r *http.Request) {
 	holdingIDStr := r.PathValue("holdingId")
 	holdingID, err := strconv.Atoi(holdingIDStr)
 	if err != nil {
-		http.Error(w, "invalid holding_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid holding_id")
 		return
 	}
 
-	if err := h.taskSvc.DeleteHolding(holdingID); err != nil {
-		h.logger.Error("failed to delete holding", "error", err)
-		http.Error(w, "failed to delete holding", http.StatusInternalServerError)
+	if err := h.taskSvc.DeleteHolding(r.Context(), holdingID); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
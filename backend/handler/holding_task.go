@@ -2,43 +2,186 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
 )
 
 // HoldingTask用のリクエスト/レスポンス型
 
 type CreateHoldingTaskRequest struct {
-	TaskName    string `json:"name"`
-	DaysBefore  int    `json:"daysBefore"`
+	TaskName string `json:"name"`
+	// リマインド日時の基準（start: 開催の初日 / end: 開催の最終日）。省略時はstart
+	Anchor models.TaskAnchor `json:"anchor"`
+	// 基準日の何日前か（負の場合は基準日の後）
+	DaysBefore int `json:"daysBefore"`
+	// 基準日時からの分単位のオフセット（負の場合は前）。指定した場合はdaysBeforeより優先する
+	OffsetMinutes *int `json:"offsetMinutes"`
+	// 日単位のタスクをリマインドする時刻（"15:04"形式）
+	RemindTime  string `json:"remindTime"`
 	Description string `json:"description"`
+	// 前提タスクのID（同じ開催のタスクのみ）
+	DependsOn []string `json:"dependsOn"`
 }
 
 func (req CreateHoldingTaskRequest) Validate() error {
 	if req.TaskName == "" {
-		return errors.New("task name is required")
-	}
-	if req.DaysBefore < 0 {
-		return errors.New("days before must be greater than or equal to 0")
+		return &services.ValidationError{Field: "name", Message: "task name is required"}
 	}
 	return nil
 }
 
+// toTask はリクエストを検証し、holdingIDの開催に追加するタスクに変換する
+func (req CreateHoldingTaskRequest) toTask(holdingID int) (models.Task, error) {
+	if err := req.Validate(); err != nil {
+		return models.Task{}, err
+	}
+	dependsOn, err := parseTaskIDs("dependsOn", req.DependsOn)
+	if err != nil {
+		return models.Task{}, err
+	}
+	remindTime, err := parseOptionalTimeOfDay("remindTime", req.RemindTime)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return models.Task{
+		HoldingID:     holdingID,
+		Name:          req.TaskName,
+		Anchor:        req.Anchor,
+		DaysBefore:    req.DaysBefore,
+		OffsetMinutes: req.OffsetMinutes,
+		RemindTime:    remindTime,
+		Description:   req.Description,
+		Status:        models.TaskStatusPending,
+		DependsOn:     dependsOn,
+	}, nil
+}
+
 type UpdateHoldingTaskRequest struct {
-	TaskName    *string `json:"name,omitempty"`
-	DaysBefore  *int    `json:"daysBefore,omitempty"`
+	TaskName *string            `json:"name,omitempty"`
+	Anchor   *models.TaskAnchor `json:"anchor,omitempty"`
+	// 指定した場合は日単位のタスクになる（offsetMinutesは解除される）
+	DaysBefore *int `json:"daysBefore,omitempty"`
+	// 指定した場合は分単位のタスクになる（remindTimeは解除される）。daysBeforeとは同時に指定できない
+	OffsetMinutes *int `json:"offsetMinutes,omitempty"`
+	// 空文字列で既定の時刻に戻す
+	RemindTime  *string `json:"remindTime,omitempty"`
 	Description *string `json:"description,omitempty"`
+	// trueで完了、falseで未完了に戻す
+	Completed *bool `json:"completed,omitempty"`
+	// 指定した場合は前提タスクを置き換える（空配列で全て解除）
+	DependsOn *[]string `json:"dependsOn,omitempty"`
+}
+
+// apply は既存のタスクに部分更新を適用したタスクを返す
+// dependsOnを指定しない場合はDependsOnをnilにし、前提タスクを書き換えない
+func (req UpdateHoldingTaskRequest) apply(existingTask models.Task) (models.Task, error) {
+	updatedTask := existingTask
+	updatedTask.DependsOn = nil
+	var err error
+
+	if req.TaskName != nil {
+		updatedTask.Name = *req.TaskName
+	}
+	if req.Anchor != nil {
+		updatedTask.Anchor = *req.Anchor
+	}
+	if req.DaysBefore != nil && req.OffsetMinutes != nil {
+		return updatedTask, &services.ValidationError{Field: "offsetMinutes", Message: "cannot be specified together with daysBefore"}
+	}
+	if req.DaysBefore != nil {
+		updatedTask.DaysBefore = *req.DaysBefore
+		updatedTask.OffsetMinutes = nil
+	}
+	if req.OffsetMinutes != nil {
+		updatedTask.OffsetMinutes = req.OffsetMinutes
+		updatedTask.RemindTime = nil
+	}
+	if req.RemindTime != nil {
+		updatedTask.RemindTime, err = parseOptionalTimeOfDay("remindTime", *req.RemindTime)
+		if err != nil {
+			return updatedTask, err
+		}
+	}
+	if req.Description != nil {
+		updatedTask.Description = *req.Description
+	}
+	if req.DependsOn != nil {
+		updatedTask.DependsOn, err = parseTaskIDs("dependsOn", *req.DependsOn)
+		if err != nil {
+			return updatedTask, err
+		}
+	}
+	if req.Completed != nil && *req.Completed != existingTask.Completed() {
+		if *req.Completed {
+			now := time.Now()
+			updatedTask.CompletedAt = &now
+		} else {
+			updatedTask.CompletedAt = nil
+		}
+	}
+	return updatedTask, nil
+}
+
+// parseTaskIDs は文字列で指定されたタスクIDを数値に変換する（重複は除く）
+func parseTaskIDs(field string, ids []string) ([]int, error) {
+	parsed := make([]int, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, &services.ValidationError{Field: field, Message: fmt.Sprintf("invalid task id %q", id)}
+		}
+		parsed = append(parsed, n)
+	}
+	slices.Sort(parsed)
+	return slices.Compact(parsed), nil
 }
 
 type HoldingTaskResponse struct {
-	TaskID      string `json:"id"`
-	HoldingID   string `json:"holdingId"`
-	TaskName    string `json:"name"`
-	DaysBefore  int    `json:"daysBefore"`
-	Description string `json:"description"`
+	TaskID        string            `json:"id"`
+	HoldingID     string            `json:"holdingId"`
+	TaskName      string            `json:"name"`
+	Anchor        models.TaskAnchor `json:"anchor"`
+	DaysBefore    int               `json:"daysBefore"`
+	OffsetMinutes *int              `json:"offsetMinutes,omitempty"`
+	RemindTime    string            `json:"remindTime,omitempty"`
+	RemindAt      *time.Time        `json:"remindAt,omitempty"`
+	Description   string            `json:"description"`
+	Status        models.TaskStatus `json:"status"`
+	Completed     bool              `json:"completed"`
+	CompletedAt   *time.Time        `json:"completedAt,omitempty"`
+	DependsOn     []string          `json:"dependsOn"`
+	Version       int               `json:"version"`
+}
+
+func newHoldingTaskResponse(task models.Task) HoldingTaskResponse {
+	dependsOn := make([]string, len(task.DependsOn))
+	for i, id := range task.DependsOn {
+		dependsOn[i] = strconv.Itoa(id)
+	}
+	return HoldingTaskResponse{
+		TaskID:        strconv.Itoa(task.ID),
+		HoldingID:     strconv.Itoa(task.HoldingID),
+		TaskName:      task.Name,
+		Anchor:        task.Anchor,
+		DaysBefore:    task.DaysBefore,
+		OffsetMinutes: task.OffsetMinutes,
+		RemindTime:    formatTimeOfDay(task.RemindTime),
+		RemindAt:      task.RemindAt,
+		Description:   task.Description,
+		Status:        task.Status,
+		Completed:     task.Completed(),
+		CompletedAt:   task.CompletedAt,
+		DependsOn:     dependsOn,
+		Version:       task.Version,
+	}
 }
 
 // GET /api/v1/holdings/{holdingId}/tasks
@@ -47,81 +190,147 @@ func (h *Handler) GetHoldingTasks(w http.ResponseWriter, r *http.Request) {
 	holdingIDStr := r.PathValue("holdingId")
 	holdingID, err := strconv.Atoi(holdingIDStr)
 	if err != nil {
-		http.Error(w, "invalid holding_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid holding_id")
 		return
 	}
 
-	tasks, err := h.taskSvc.GetTasksByHoldingID(holdingID)
+	tasks, err := h.taskSvc.GetTasksByHoldingID(r.Context(), holdingID)
 	if err != nil {
-		h.logger.Error("failed to get holding tasks", "error", err)
-		http.Error(w, "failed to get holding tasks", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
 	// レスポンス変換
 	response := make([]HoldingTaskResponse, len(tasks))
 	for i, task := range tasks {
-		response[i] = HoldingTaskResponse{
-			TaskID:      strconv.Itoa(task.ID),
-			HoldingID:   strconv.Itoa(task.HoldingID),
-			TaskName:    task.Name,
-			DaysBefore:  task.DaysBefore,
-			Description: task.Description,
-		}
+		response[i] = newHoldingTaskResponse(task)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /api/v1/tasks
+// 開催をまたいでタスクを取得（クエリパラメータで絞り込み・並び替え・ページネーションが可能）
+func (h *Handler) GetTasks(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTaskFilter(r.URL.Query())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	page, err := h.taskSvc.ListTasks(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := make([]HoldingTaskResponse, len(page.Items))
+	for i, task := range page.Items {
+		response[i] = newHoldingTaskResponse(task)
 	}
 
+	setNextCursor(w, page.NextCursor)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
+func parseTaskFilter(q url.Values) (services.TaskFilter, error) {
+	var filter services.TaskFilter
+	var err error
+
+	if filter.HoldingID, err = parseOptionalInt(q, "holding_id"); err != nil {
+		return filter, err
+	}
+	if filter.EventID, err = parseOptionalInt(q, "event_id"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseOptionalDate(q, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseOptionalDate(q, "to"); err != nil {
+		return filter, err
+	}
+	if filter.ListOptions, err = parseListOptions(q); err != nil {
+		return filter, err
+	}
+	if filter.IncludeArchived, err = parseOptionalBool(q, "include_archived"); err != nil {
+		return filter, err
+	}
+	filter.Status = models.TaskStatus(q.Get("status"))
+	if filter.Status != "" && !filter.Status.Valid() {
+		return filter, &services.ValidationError{Field: "status", Message: "unknown task status"}
+	}
+	filter.ChannelID = q.Get("channel_id")
+	filter.When = q.Get("when")
+	return filter, nil
+}
+
 // POST /api/v1/holdings/{holdingId}/tasks
 // 特定の開催にカスタムタスクを追加
 func (h *Handler) CreateHoldingTask(w http.ResponseWriter, r *http.Request) {
 	holdingIDStr := r.PathValue("holdingId")
 	holdingID, err := strconv.Atoi(holdingIDStr)
 	if err != nil {
-		http.Error(w, "invalid holding_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid holding_id")
 		return
 	}
 
 	var req CreateHoldingTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid request body")
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	task, err := req.toTask(holdingID)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	task := models.Task{
-		HoldingID:   holdingID,
-		Name:        req.TaskName,
-		DaysBefore:  req.DaysBefore,
-		Description: req.Description,
+	taskID, err := h.taskSvc.CreateTask(r.Context(), task)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
-	taskID, err := h.taskSvc.CreateTask(task)
+	// リマインド日時などサービスで計算された値を含めて返す
+	task, err = h.taskSvc.GetTaskByID(r.Context(), taskID)
 	if err != nil {
-		h.logger.Error("failed to create holding task", "error", err)
-		http.Error(w, "failed to create holding task", http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
-	task.ID = taskID
+	response := newHoldingTaskResponse(task)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /api/v1/holding-tasks/{taskId}
+// 特定の開催タスクを取得
+func (h *Handler) GetHoldingTask(w http.ResponseWriter, r *http.Request) {
+	taskIDStr := r.PathValue("taskId")
+	taskID, err := strconv.Atoi(taskIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid task_id")
+		return
+	}
 
-	response := HoldingTaskResponse{
-		TaskID:      strconv.Itoa(task.ID),
-		HoldingID:   strconv.Itoa(task.HoldingID),
-		TaskName:    task.Name,
-		DaysBefore:  task.DaysBefore,
-		Description: task.Description,
+	task, err := h.taskSvc.GetTaskByID(r.Context(), taskID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
+	response := newHoldingTaskResponse(task)
+
+	setETag(w, task.Version)
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -131,79 +340,76 @@ func (h *Handler) UpdateHoldingTask(w http.ResponseWriter, r *http.Request) {
 	taskIDStr := r.PathValue("taskId")
 	taskID, err := strconv.Atoi(taskIDStr)
 	if err != nil {
-		http.Error(w, "invalid task_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid task_id")
 		return
 	}
 
 	var req UpdateHoldingTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
 	// 既存のタスクを取得
-	existingTask, err := h.taskSvc.GetTaskByID(taskID)
+	existingTask, err := h.taskSvc.GetTaskByID(r.Context(), taskID)
 	if err != nil {
-		h.logger.Error("failed to get holding task", "error", err)
-		http.Error(w, "holding task not found", http.StatusNotFound)
+		h.writeError(w, r, err)
 		return
 	}
 
 	// 部分更新の適用
-	updatedTask := models.Task{
-		ID:          existingTask.ID,
-		HoldingID:   existingTask.HoldingID,
-		Name:        existingTask.Name,
-		DaysBefore:  existingTask.DaysBefore,
-		Description: existingTask.Description,
+	updatedTask, err := req.apply(existingTask)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
-
-	if req.TaskName != nil {
-		updatedTask.Name = *req.TaskName
+	// If-Matchが無い場合も読み込んだ時点の版（applyで引き継がれる）で確かめ、
+	// 書き込むまでに他の更新が入っていれば上書きしない
+	ifMatch := version != 0
+	if ifMatch {
+		updatedTask.Version = version
 	}
-	if req.DaysBefore != nil {
-		if *req.DaysBefore < 0 {
-			http.Error(w, "days_before must be greater than or equal to 0", http.StatusBadRequest)
-			return
+
+	if err := h.taskSvc.UpdateTask(r.Context(), taskID, updatedTask); err != nil {
+		if !ifMatch {
+			err = implicitConflict(err, "task", taskID)
 		}
-		updatedTask.DaysBefore = *req.DaysBefore
-	}
-	if req.Description != nil {
-		updatedTask.Description = *req.Description
+		h.writeError(w, r, err)
+		return
 	}
 
-	if err := h.taskSvc.UpdateTask(taskID, updatedTask); err != nil {
-		h.logger.Error("failed to update holding task", "error", err)
-		http.Error(w, "failed to update holding task", http.StatusInternalServerError)
+	updatedTask, err = h.taskSvc.GetTaskByID(r.Context(), taskID)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	response := HoldingTaskResponse{
-		TaskID:      strconv.Itoa(updatedTask.ID),
-		HoldingID:   strconv.Itoa(updatedTask.HoldingID),
-		TaskName:    updatedTask.Name,
-		DaysBefore:  updatedTask.DaysBefore,
-		Description: updatedTask.Description,
-	}
+	response := newHoldingTaskResponse(updatedTask)
 
+	setETag(w, updatedTask.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
 // DELETE /api/v1/holding-tasks/{taskId}
-// 特定の開催タスクを削除
+// 特定の開催タスクをゴミ箱に入れる
 func (h *Handler) DeleteHoldingTask(w http.ResponseWriter, r *http.Request) {
 	taskIDStr := r.PathValue("taskId")
 	taskID, err := strconv.Atoi(taskIDStr)
 	if err != nil {
-		http.Error(w, "invalid task_id", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid task_id")
 		return
 	}
 
-	if err := h.taskSvc.DeleteTask(taskID); err != nil {
-		h.logger.Error("failed to delete holding task", "error", err)
-		http.Error(w, "failed to delete holding task", http.StatusInternalServerError)
+	if err := h.taskSvc.DeleteTask(r.Context(), taskID); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
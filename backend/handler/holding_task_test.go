@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+func TestUpdateHoldingTaskRequestApplyDependsOn(t *testing.T) {
+	existing := models.Task{ID: 3, Name: "task", DependsOn: []int{1, 2}}
+	name := "renamed"
+	completed := true
+	none := []string{}
+	replaced := []string{"5", "4", "5"}
+
+	tests := []struct {
+		name string
+		req  UpdateHoldingTaskRequest
+		want []int
+	}{
+		// nilの場合は前提タスクを書き換えない
+		{name: "name only", req: UpdateHoldingTaskRequest{TaskName: &name}, want: nil},
+		{name: "completed only", req: UpdateHoldingTaskRequest{Completed: &completed}, want: nil},
+		{name: "clear", req: UpdateHoldingTaskRequest{DependsOn: &none}, want: []int{}},
+		{name: "replace", req: UpdateHoldingTaskRequest{DependsOn: &replaced}, want: []int{4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.apply(existing)
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+			if (got.DependsOn == nil) != (tt.want == nil) || !slices.Equal(got.DependsOn, tt.want) {
+				t.Errorf("apply() DependsOn = %#v, want %#v", got.DependsOn, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateHoldingTaskRequestApplyRejectsBothOffsets(t *testing.T) {
+	days := 3
+	minutes := -60
+	req := UpdateHoldingTaskRequest{DaysBefore: &days, OffsetMinutes: &minutes}
+
+	_, err := req.apply(models.Task{ID: 1, DaysBefore: 1})
+	var verr *services.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "offsetMinutes" {
+		t.Errorf("apply() error = %v, want ValidationError on offsetMinutes", err)
+	}
+}
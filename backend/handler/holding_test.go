@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+func TestResolveLegacyMentionWithoutLookup(t *testing.T) {
+	mentions := []models.HoldingMention{{Type: models.MentionTypeUser, TargetID: "u1"}}
+	legacy := "@alice"
+	blank := " "
+
+	tests := []struct {
+		name           string
+		mentions       []models.HoldingMention
+		mention        *string
+		want           []models.HoldingMention
+		wantDeprecated bool
+	}{
+		{name: "mentions only", mentions: mentions, want: mentions},
+		{name: "mention is ignored when mentions is given", mentions: mentions, mention: &legacy, want: mentions, wantDeprecated: true},
+		{name: "blank mention leaves mentions unchanged", mention: &blank, wantDeprecated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// traQに問い合わせない場合だけを確かめるので、traqSvcは設定しない
+			h := &Handler{}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/holdings", nil)
+
+			got, err := h.resolveLegacyMention(w, r, tt.mentions, tt.mention)
+			if err != nil {
+				t.Fatalf("resolveLegacyMention() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("resolveLegacyMention() = %v, want %v", got, tt.want)
+			}
+			if deprecated := w.Header().Get("Deprecation") != ""; deprecated != tt.wantDeprecated {
+				t.Errorf("Deprecation header set = %v, want %v", deprecated, tt.wantDeprecated)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// 一覧系のクエリパラメータの解析
+
+func parseListOptions(q url.Values) (services.ListOptions, error) {
+	opts := services.ListOptions{
+		Sort:   q.Get("sort"),
+		Cursor: q.Get("cursor"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return opts, &services.ValidationError{Field: "limit", Message: "must be a positive integer"}
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+func parseOptionalInt(q url.Values, name string) (*int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, &services.ValidationError{Field: name, Message: "must be an integer"}
+	}
+	return &n, nil
+}
+
+func parseOptionalDate(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+	if err != nil {
+		return nil, &services.ValidationError{Field: name, Message: "must be in YYYY-MM-DD format"}
+	}
+	return &t, nil
+}
+
+func parseOptionalBool(q url.Values, name string) (bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, &services.ValidationError{Field: name, Message: "must be true or false"}
+	}
+	return b, nil
+}
+
+// setNextCursor は次のページがある場合にカーソルをヘッダーで返す
+// ボディは配列のままにして、ページネーションを使わないクライアントとの互換性を保つ
+func setNextCursor(w http.ResponseWriter, cursor string) {
+	if cursor != "" {
+		w.Header().Set("X-Next-Cursor", cursor)
+	}
+}
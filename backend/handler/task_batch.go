@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pirosiki197/event_reminder/models"
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// タスクの一括操作用のリクエスト/レスポンス型
+
+type BatchHoldingTasksRequest struct {
+	Create []CreateHoldingTaskRequest      `json:"create"`
+	Update []BatchUpdateHoldingTaskRequest `json:"update"`
+	Delete []string                        `json:"delete"`
+	// 全てのタスクのリマインド日をこの日数だけ前にずらす（負の場合は後ろ）
+	ShiftDays int `json:"shiftDays"`
+}
+
+type BatchUpdateHoldingTaskRequest struct {
+	TaskID string `json:"id"`
+	UpdateHoldingTaskRequest
+}
+
+type BatchHoldingTasksResponse struct {
+	// 作成したタスクのID（createと同じ順序）
+	Created []string `json:"created"`
+	// 操作後の開催の全てのタスク
+	Tasks []HoldingTaskResponse `json:"tasks"`
+}
+
+// PATCH /api/v1/holdings/{holdingId}/tasks
+// 開催のタスクの作成・更新・削除と日数のずらしを1つのトランザクションでまとめて行う
+func (h *Handler) BatchHoldingTasks(w http.ResponseWriter, r *http.Request) {
+	holdingIDStr := r.PathValue("holdingId")
+	holdingID, err := strconv.Atoi(holdingIDStr)
+	if err != nil {
+		writeBadRequest(w, r, "invalid holding_id")
+		return
+	}
+
+	var req BatchHoldingTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "invalid request body")
+		return
+	}
+
+	if _, err := h.taskSvc.GetHoldingByID(r.Context(), holdingID); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// 部分更新を適用するために既存のタスクを取得
+	existingTasks, err := h.taskSvc.GetTasksByHoldingID(r.Context(), holdingID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	existingByID := make(map[int]models.Task, len(existingTasks))
+	for _, task := range existingTasks {
+		existingByID[task.ID] = task
+	}
+
+	// 不正な項目は1つずつ返さず、全て集めてから返す
+	var batchErr services.BatchValidationError
+	batch := services.TaskBatch{ShiftDays: req.ShiftDays}
+
+	for i, createReq := range req.Create {
+		task, err := createReq.toTask(holdingID)
+		if err != nil {
+			batchErr.Add(fmt.Sprintf("create.%d", i), err)
+			continue
+		}
+		batch.Create = append(batch.Create, task)
+	}
+	for i, updateReq := range req.Update {
+		path := fmt.Sprintf("update.%d", i)
+		taskID, err := strconv.Atoi(updateReq.TaskID)
+		if err != nil {
+			batchErr.Add(path, &services.ValidationError{Field: "id", Message: fmt.Sprintf("invalid task id %q", updateReq.TaskID)})
+			continue
+		}
+		existingTask, ok := existingByID[taskID]
+		if !ok {
+			batchErr.Add(path, &services.ValidationError{Field: "id", Message: fmt.Sprintf("task %d does not belong to this holding", taskID)})
+			continue
+		}
+		task, err := updateReq.apply(existingTask)
+		if err != nil {
+			batchErr.Add(path, err)
+			continue
+		}
+		batch.Update = append(batch.Update, task)
+	}
+	for i, id := range req.Delete {
+		taskID, err := strconv.Atoi(id)
+		if err != nil {
+			batchErr.Add(fmt.Sprintf("delete.%d", i), &services.ValidationError{Message: fmt.Sprintf("invalid task id %q", id)})
+			continue
+		}
+		batch.Delete = append(batch.Delete, taskID)
+	}
+	if err := batchErr.Err(); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// 更新するタスクは読み込んだ時点の版で確かめる
+	createdIDs, err := h.taskSvc.ApplyTaskBatch(r.Context(), holdingID, batch)
+	if err != nil {
+		h.writeError(w, r, implicitConflict(err, "tasks of holding", holdingID))
+		return
+	}
+
+	tasks, err := h.taskSvc.GetTasksByHoldingID(r.Context(), holdingID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := BatchHoldingTasksResponse{
+		Created: make([]string, len(createdIDs)),
+		Tasks:   make([]HoldingTaskResponse, len(tasks)),
+	}
+	for i, id := range createdIDs {
+		response.Created[i] = strconv.Itoa(id)
+	}
+	for i, task := range tasks {
+		response.Tasks[i] = newHoldingTaskResponse(task)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+// 日付・時刻（開催の最終日・開始時刻、タスクのリマインド時刻）の変換
+
+const timeOfDayLayout = "15:04"
+
+// parseTimeOfDay は"15:04"形式の時刻をDBに保存する"15:04:05"形式に変換する
+func parseTimeOfDay(field, v string) (string, error) {
+	t, err := time.Parse(timeOfDayLayout, v)
+	if err != nil {
+		return "", &services.ValidationError{Field: field, Message: "must be in HH:MM format"}
+	}
+	return t.Format(time.TimeOnly), nil
+}
+
+// parseOptionalTimeOfDay は空文字列の場合にnil（未設定）を返す
+func parseOptionalTimeOfDay(field, v string) (*string, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := parseTimeOfDay(field, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// formatTimeOfDay はDBの"15:04:05"形式の時刻を"15:04"形式にする
+func formatTimeOfDay(v *string) string {
+	if v == nil {
+		return ""
+	}
+	t, err := time.Parse(time.TimeOnly, *v)
+	if err != nil {
+		return *v
+	}
+	return t.Format(timeOfDayLayout)
+}
+
+// parseOptionalDateField は"2006-01-02"形式の日付を変換する。空文字列の場合はnil（未設定）を返す
+func parseOptionalDateField(field, v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, &services.ValidationError{Field: field, Message: "must be in YYYY-MM-DD format"}
+	}
+	return &t, nil
+}
+
+func formatOptionalDate(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.Format(time.DateOnly)
+}
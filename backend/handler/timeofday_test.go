@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pirosiki197/event_reminder/services"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "09:30", want: "09:30:00"},
+		{in: "9:05", want: "09:05:00"},
+		{in: "23:59", want: "23:59:00"},
+		{in: "24:00", wantErr: true},
+		{in: "09:30:00", wantErr: true},
+		{in: "noon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseTimeOfDay("remindTime", tt.in)
+			if tt.wantErr {
+				var verr *services.ValidationError
+				if !errors.As(err, &verr) || verr.Field != "remindTime" {
+					t.Errorf("parseTimeOfDay(%q) error = %v, want ValidationError on remindTime", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseTimeOfDay(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimeOfDay(t *testing.T) {
+	v := "08:00:00"
+	if got := formatTimeOfDay(&v); got != "08:00" {
+		t.Errorf("formatTimeOfDay(%q) = %q, want %q", v, got, "08:00")
+	}
+	if got := formatTimeOfDay(nil); got != "" {
+		t.Errorf("formatTimeOfDay(nil) = %q, want empty", got)
+	}
+}
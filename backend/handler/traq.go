@@ -1,15 +1,41 @@
 package handler
 
 import (
+	"log/slog"
 	"net/http"
+
+	"github.com/pirosiki197/event_reminder/problem"
 )
 
 func (h *Handler) GetChannelList(w http.ResponseWriter, r *http.Request) {
 	channels, err := h.traqSvc.GetChannelList(r.Context())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		h.logger.Error("failed to get channel list", slog.String("err", err.Error()))
+		problem.Write(w, r, problem.New(http.StatusBadGateway, "failed to get channels from traQ"))
 		return
 	}
 
 	jsonEncoded(w, channels)
 }
+
+func (h *Handler) GetUserList(w http.ResponseWriter, r *http.Request) {
+	users, err := h.traqSvc.GetUserList(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get user list", slog.String("err", err.Error()))
+		problem.Write(w, r, problem.New(http.StatusBadGateway, "failed to get users from traQ"))
+		return
+	}
+
+	jsonEncoded(w, users)
+}
+
+func (h *Handler) GetUserGroupList(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.traqSvc.GetUserGroupList(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get user group list", slog.String("err", err.Error()))
+		problem.Write(w, r, problem.New(http.StatusBadGateway, "failed to get user groups from traQ"))
+		return
+	}
+
+	jsonEncoded(w, groups)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ゴミ箱用のレスポンス型
+
+type TrashedEventResponse struct {
+	models.Event
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+type TrashedHoldingResponse struct {
+	HoldingResponse
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+type TrashedTaskResponse struct {
+	HoldingTaskResponse
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+type TrashResponse struct {
+	Events   []TrashedEventResponse   `json:"events"`
+	Holdings []TrashedHoldingResponse `json:"holdings"`
+	Tasks    []TrashedTaskResponse    `json:"tasks"`
+}
+
+// GET /api/v1/trash
+// ゴミ箱の中身を取得（イベントや開催と一緒にゴミ箱に入れたものは含まない）
+func (h *Handler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	trash, err := h.taskSvc.GetTrash(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	response := TrashResponse{
+		Events:   make([]TrashedEventResponse, len(trash.Events)),
+		Holdings: make([]TrashedHoldingResponse, len(trash.Holdings)),
+		Tasks:    make([]TrashedTaskResponse, len(trash.Tasks)),
+	}
+	for i, event := range trash.Events {
+		response.Events[i] = TrashedEventResponse{Event: event, DeletedAt: event.DeletedAt}
+	}
+	for i, holding := range trash.Holdings {
+		response.Holdings[i] = TrashedHoldingResponse{HoldingResponse: newHoldingResponse(holding), DeletedAt: holding.DeletedAt}
+	}
+	for i, task := range trash.Tasks {
+		response.Tasks[i] = TrashedTaskResponse{HoldingTaskResponse: newHoldingTaskResponse(task), DeletedAt: task.DeletedAt}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// POST /api/v1/events/{eventId}/restore
+// ゴミ箱からイベントを、一緒に入れた開催とタスクごと復元
+func (h *Handler) RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("eventId"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid event_id")
+		return
+	}
+
+	if err := h.taskSvc.RestoreEvent(r.Context(), id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/v1/holdings/{holdingId}/restore
+// ゴミ箱から開催を、一緒に入れたタスクごと復元（イベントがゴミ箱にある場合は409）
+func (h *Handler) RestoreHolding(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("holdingId"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid holding_id")
+		return
+	}
+
+	if err := h.taskSvc.RestoreHolding(r.Context(), id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/v1/holding-tasks/{taskId}/restore
+// ゴミ箱からタスクを復元（開催がゴミ箱にある場合は409）
+func (h *Handler) RestoreHoldingTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("taskId"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid task_id")
+		return
+	}
+
+	if err := h.taskSvc.RestoreTask(r.Context(), id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,23 +1,36 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/pirosiki197/event_reminder/handler"
 	"github.com/pirosiki197/event_reminder/services"
+	"github.com/pirosiki197/event_reminder/tracing"
 	"github.com/traPtitech/go-traq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
+// 終了シグナルを受けてから、処理中のリクエストやリマインド送信の完了を待つ時間
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	traqConf := traq.NewConfiguration()
 	traqConf.DefaultHeader = map[string]string{
 		"Authorization": fmt.Sprintf("Bearer %s", os.Getenv("TRAQ_TOKEN")),
@@ -32,25 +45,139 @@ func main() {
 		DBName:               os.Getenv("DB_NAME"),
 		ParseTime:            true,
 		AllowNativePasswords: true,
-		Loc:                  time.Local,
+		// UPDATEで値が変わらなかった行も更新対象として数える（対象が存在しない場合と区別するため）
+		ClientFoundRows: true,
+		Loc:             time.Local,
 	}
-	db, err := sqlx.Open("mysql", dbConf.FormatDSN())
+	shutdownTracing, err := tracing.Setup(ctx)
 	if err != nil {
-		panic(err)
+		logger.Error("failed to setup tracing", slog.String("err", err.Error()))
+		os.Exit(1)
 	}
-	if err := db.Ping(); err != nil {
-		panic(err)
+
+	db, err := connectDB(ctx, dbConf.FormatDSN(), logger)
+	if err != nil {
+		logger.Error("failed to connect to db", slog.String("err", err.Error()))
+		os.Exit(1)
 	}
 
 	taskService := services.NewTaskService(db, logger)
 	traqService := services.NewTraQService(traqClient)
 
-	remindService := services.NewRemindService(taskService, traqService, logger, traqClient)
+	if err := taskService.MigrateLegacyTaskStatus(ctx); err != nil {
+		logger.Error("failed to migrate legacy task status", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	if err := taskService.BackfillRemindAt(ctx); err != nil {
+		logger.Error("failed to backfill remind_at", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	// traQに繋がらない場合は旧形式のメンションのまま動かし、次の起動時に移行する
+	if err := taskService.BackfillHoldingMentions(ctx, traqService); err != nil {
+		logger.Warn("failed to backfill holding mentions", slog.String("err", err.Error()))
+	}
+
+	deliveryMode, err := services.ParseDeliveryMode(os.Getenv("REMIND_DELIVERY"))
+	if err != nil {
+		logger.Error("invalid REMIND_DELIVERY", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	remindInterval, err := services.ParseRemindInterval(os.Getenv("REMIND_INTERVAL"))
+	if err != nil {
+		logger.Error("invalid REMIND_INTERVAL", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	trashRetention, err := services.ParseTrashRetention(os.Getenv("TRASH_RETENTION"))
+	if err != nil {
+		logger.Error("invalid TRASH_RETENTION", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	trustedProxies, err := handler.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		logger.Error("invalid TRUSTED_PROXIES", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	remindService := services.NewRemindService(taskService, traqService, logger, traqClient, deliveryMode, remindInterval, trashRetention)
 	remindService.Start()
 
-	h := handler.New(taskService, traqService, logger)
+	h := handler.New(taskService, traqService, remindService, logger, trustedProxies)
 	r := chi.NewRouter()
-	h.SetupRoutes(r)
-	logger.Info("server started")
-	http.ListenAndServe(":8080", r)
+	if err := h.SetupRoutes(r); err != nil {
+		logger.Error("failed to setup routes", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	srv := &http.Server{
+		Addr:    ":8080",
+		Handler: r,
+	}
+	go func() {
+		logger.Info("server started")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("server stopped unexpectedly", slog.String("err", err.Error()))
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to shutdown server", slog.String("err", err.Error()))
+	}
+	if err := remindService.Stop(shutdownCtx); err != nil {
+		logger.Error("failed to stop remind service", slog.String("err", err.Error()))
+	}
+	if err := db.Close(); err != nil {
+		logger.Error("failed to close db", slog.String("err", err.Error()))
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("failed to flush traces", slog.String("err", err.Error()))
+	}
+	logger.Info("server stopped")
+}
+
+const (
+	dbConnectMaxAttempts = 10
+	dbConnectMaxBackoff  = 30 * time.Second
+)
+
+// DBが起動しきっていない場合に備えて、指数バックオフで接続を再試行する
+func connectDB(ctx context.Context, dsn string, logger *slog.Logger) (*sqlx.DB, error) {
+	// クエリごとのスパンを記録するためにotelsqlでドライバを包む
+	sqlDB, err := otelsql.Open("mysql", dsn, otelsql.WithAttributes(semconv.DBSystemNameMySQL))
+	if err != nil {
+		return nil, err
+	}
+	db := sqlx.NewDb(sqlDB, "mysql")
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			return db, nil
+		}
+		if attempt == dbConnectMaxAttempts {
+			break
+		}
+
+		logger.Warn("failed to ping db, retrying",
+			slog.String("err", err.Error()),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+		)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, dbConnectMaxBackoff)
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("gave up after %d attempts: %w", dbConnectMaxAttempts, err)
 }
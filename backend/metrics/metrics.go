@@ -0,0 +1,131 @@
+// Package metrics はPrometheus向けのメトリクスを定義する
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/motoki317/sc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "event_reminder"
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by route and status code.",
+	}, []string{"method", "route", "status"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	RemindPassDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "remind_pass_duration_seconds",
+		Help:      "Duration of a reminder pass.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+	RemindPassesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remind_passes_total",
+		Help:      "Number of reminder passes by result.",
+	}, []string{"result"})
+	RemindTasksSelected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remind_tasks_selected_total",
+		Help:      "Number of tasks claimed for reminding.",
+	})
+	RemindTasksSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remind_tasks_sent_total",
+		Help:      "Number of tasks whose reminder was sent.",
+	})
+	RemindTasksSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remind_tasks_skipped_total",
+		Help:      "Number of tasks skipped by the catch-up policy.",
+	})
+	RemindTasksFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remind_tasks_failed_total",
+		Help:      "Number of tasks whose reminder failed, by reason.",
+	}, []string{"reason"})
+
+	traqRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "traq_request_duration_seconds",
+		Help:      "Latency of traQ API calls by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	traqRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "traq_request_errors_total",
+		Help:      "Number of failed traQ API calls by operation.",
+	}, []string{"operation"})
+)
+
+// Handler は/metricsで公開するハンドラを返す
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware はchiのルートパターンごとにリクエスト数とレイテンシを記録する
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+		next.ServeHTTP(ww, r)
+
+		// ルーティング後でないとパターンが確定しない
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveTraQRequest はtraQ API呼び出しのレイテンシとエラーを記録する
+func ObserveTraQRequest(operation string, start time.Time, err error) {
+	traqRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		traqRequestErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// RegisterCacheStats はscのキャッシュのヒット数・ミス数を公開する
+func RegisterCacheStats(cacheName string, stats func() sc.Stats) {
+	labels := prometheus.Labels{"cache": cacheName}
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Name:        "cache_hits_total",
+		Help:        "Number of cache hits, including stale hits.",
+		ConstLabels: labels,
+	}, func() float64 {
+		s := stats()
+		return float64(s.Hits + s.GraceHits)
+	})
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Name:        "cache_misses_total",
+		Help:        "Number of cache misses.",
+		ConstLabels: labels,
+	}, func() float64 {
+		return float64(stats().Misses)
+	})
+}
@@ -1,7 +1,16 @@
 CREATE TABLE `events` (
     `id` INT NOT NULL AUTO_INCREMENT,
     `name` VARCHAR(255) NOT NULL,
-    PRIMARY KEY (`id`)
+    `catch_up_policy` ENUM('all', 'summary', 'skip') NOT NULL DEFAULT 'all',
+    `blocked_policy` ENUM('notify', 'defer') NOT NULL DEFAULT 'notify',
+    -- アーカイブした時刻（NULLでなければ開催とタスクごと既定の一覧とリマインドの対象外）
+    `archived_at` DATETIME DEFAULT NULL,
+    -- 楽観的排他制御のための版（APIから更新するたびに1増える）
+    `version` INT NOT NULL DEFAULT 1,
+    -- ゴミ箱に入れた時刻（NULLでなければ通常の取得とリマインドの対象外）
+    `deleted_at` DATETIME DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_event_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
 CREATE TABLE `holdings` (
@@ -9,9 +18,22 @@ CREATE TABLE `holdings` (
     `event_id` INT NOT NULL,
     `name` VARCHAR(255) NOT NULL,
     `date` DATE NOT NULL,
+    -- 開始時刻（NULLの場合は日単位の開催として扱い、分単位のオフセットは0:00からの相対になる）
+    `start_time` TIME DEFAULT NULL,
+    -- 複数日にわたる開催の最終日（NULLの場合は1日のみの開催）
+    `end_date` DATE DEFAULT NULL,
     `channel_id` VARCHAR(50) NOT NULL,
-    `mention` VARCHAR(255) NOT NULL,
+    -- 定期的な確認で分かったチャンネルの状態（ok / archived: アーカイブ済み / not_found: 存在しない）
+    `channel_status` ENUM('ok', 'archived', 'not_found') NOT NULL DEFAULT 'ok',
+    `channel_checked_at` DATETIME DEFAULT NULL,
+    -- 旧形式の自由記述のメンション（holding_mentionsが無い場合のみメッセージに使う）
+    `mention` VARCHAR(255) NOT NULL DEFAULT '',
+    -- 楽観的排他制御のための版（APIから更新するたびに1増える）
+    `version` INT NOT NULL DEFAULT 1,
+    -- ゴミ箱に入れた時刻。イベントと一緒に入れた場合はイベントと同じ時刻になる
+    `deleted_at` DATETIME DEFAULT NULL,
     PRIMARY KEY (`id`),
+    KEY `idx_holding_deleted_at` (`deleted_at`),
     CONSTRAINT `fk_holding_event_id` FOREIGN KEY (`event_id`) REFERENCES `events`(`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
@@ -19,9 +41,90 @@ CREATE TABLE `tasks` (
     `id` INT NOT NULL AUTO_INCREMENT,
     `holding_id` INT NOT NULL,
     `name` VARCHAR(255) NOT NULL,
+    -- リマインド日時の基準（start: 開催の初日 / end: 開催の最終日）
+    `anchor` ENUM('start', 'end') NOT NULL DEFAULT 'start',
+    -- 基準日の何日前か（負の場合は基準日の後）。offset_minutesがある場合はremind_atから求めた値
     `days_before` INT NOT NULL,
+    -- 基準日時からの分単位のオフセット（負の場合は前）。基準日時は初日なら開始日時、最終日ならその日の0:00
+    `offset_minutes` INT DEFAULT NULL,
+    -- 日単位のタスクをリマインドする時刻（NULLの場合は既定の時刻）
+    `remind_time` TIME DEFAULT NULL,
+    -- 上記から計算したリマインド日時（TaskServiceが更新する）
+    `remind_at` DATETIME DEFAULT NULL,
     `description` TEXT,
+    `status` ENUM('pending', 'sending', 'sent', 'failed', 'skipped') NOT NULL DEFAULT 'pending',
+    `attempts` INT NOT NULL DEFAULT 0,
+    `last_error` TEXT,
+    `sent_at` DATETIME DEFAULT NULL,
+    -- 作成時刻（列の追加前からあるタスクはNULLで、作成時点で期限切れだったかは分からないため通常どおり送る）
+    `created_at` DATETIME DEFAULT NULL,
+    `completed_at` DATETIME DEFAULT NULL,
+    `claimed_by` VARCHAR(64) DEFAULT NULL,
+    `claimed_at` DATETIME DEFAULT NULL,
+    `idempotency_key` VARCHAR(128) DEFAULT NULL,
+    -- 旧フラグ: 起動時にstatusへ移行される。全環境で移行が済んだら削除する
     `reminded` BOOLEAN NOT NULL DEFAULT false,
+    `skipped` BOOLEAN NOT NULL DEFAULT false,
+    -- 楽観的排他制御のための版（APIから更新するたびに1増える。リマインドの送信状態の変化では増えない）
+    `version` INT NOT NULL DEFAULT 1,
+    -- ゴミ箱に入れた時刻。開催と一緒に入れた場合は開催と同じ時刻になる
+    `deleted_at` DATETIME DEFAULT NULL,
     PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_task_idempotency_key` (`idempotency_key`),
+    KEY `idx_task_status_remind_at` (`status`, `remind_at`),
+    KEY `idx_task_deleted_at` (`deleted_at`),
     CONSTRAINT `fk_task_holding_id` FOREIGN KEY (`holding_id`) REFERENCES `holdings`(`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 開催のリマインドでメンションするtraQのユーザー・グループ（positionの昇順に並べる）
+CREATE TABLE `holding_mentions` (
+    `holding_id` INT NOT NULL,
+    `position` INT NOT NULL,
+    `type` ENUM('user', 'group') NOT NULL,
+    -- traQのユーザーまたはユーザーグループのUUID
+    `target_id` CHAR(36) NOT NULL,
+    PRIMARY KEY (`holding_id`, `position`),
+    UNIQUE KEY `uk_holding_mention_target` (`holding_id`, `type`, `target_id`),
+    CONSTRAINT `fk_holding_mention_holding_id` FOREIGN KEY (`holding_id`) REFERENCES `holdings`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- タスクのチェックリスト（positionの昇順に並ぶ）
+CREATE TABLE `task_checklist_items` (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `task_id` INT NOT NULL,
+    `position` INT NOT NULL,
+    `text` VARCHAR(255) NOT NULL,
+    `done_at` DATETIME DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_checklist_task_position` (`task_id`, `position`),
+    CONSTRAINT `fk_checklist_task_id` FOREIGN KEY (`task_id`) REFERENCES `tasks`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- タスクへのコメント（authorはtraQのユーザー名）
+CREATE TABLE `task_comments` (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `task_id` INT NOT NULL,
+    `author` VARCHAR(32) NOT NULL,
+    `body` TEXT NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_comment_task_created_at` (`task_id`, `created_at`),
+    CONSTRAINT `fk_comment_task_id` FOREIGN KEY (`task_id`) REFERENCES `tasks`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- タスクの前提タスク（task_idはdepends_on_task_idの完了後に着手できる）
+-- 同じ開催のタスク同士でのみ設定でき、循環は保存時に検出する
+CREATE TABLE `task_dependencies` (
+    `task_id` INT NOT NULL,
+    `depends_on_task_id` INT NOT NULL,
+    PRIMARY KEY (`task_id`, `depends_on_task_id`),
+    CONSTRAINT `fk_task_dependency_task_id` FOREIGN KEY (`task_id`) REFERENCES `tasks`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_task_dependency_depends_on_task_id` FOREIGN KEY (`depends_on_task_id`) REFERENCES `tasks`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `scheduler_runs` (
+    `name` VARCHAR(64) NOT NULL,
+    `last_run_at` DATETIME NOT NULL,
+    PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
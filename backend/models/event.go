@@ -4,16 +4,136 @@ import (
 	"time"
 )
 
+// CatchUpPolicy は作成時点で既にリマインド日を過ぎていたタスクと、停止中にリマインド日時を迎えたタスクの扱いを表す
+type CatchUpPolicy string
+
+const (
+	// 通常どおり個別にリマインドする
+	CatchUpPolicyAll CatchUpPolicy = "all"
+	// 期限切れのタスクをまとめて1件のメッセージで通知する
+	CatchUpPolicySummary CatchUpPolicy = "summary"
+	// リマインドせずにスキップ扱いにする
+	CatchUpPolicySkip CatchUpPolicy = "skip"
+)
+
+func (p CatchUpPolicy) Valid() bool {
+	switch p {
+	case CatchUpPolicyAll, CatchUpPolicySummary, CatchUpPolicySkip:
+		return true
+	}
+	return false
+}
+
+// BlockedPolicy は前提タスクが完了していないタスクのリマインドの扱いを表す
+type BlockedPolicy string
+
+const (
+	// リマインドに未完了の前提タスクを添えて通知する
+	BlockedPolicyNotify BlockedPolicy = "notify"
+	// 前提タスクがすべて完了するまでリマインドを遅らせる
+	BlockedPolicyDefer BlockedPolicy = "defer"
+)
+
+func (p BlockedPolicy) Valid() bool {
+	switch p {
+	case BlockedPolicyNotify, BlockedPolicyDefer:
+		return true
+	}
+	return false
+}
+
+// ChannelStatus は開催の通知先チャンネルのtraQでの状態を表す
+type ChannelStatus string
+
+const (
+	ChannelStatusOK ChannelStatus = "ok"
+	// アーカイブされていて投稿できない
+	ChannelStatusArchived ChannelStatus = "archived"
+	// 削除されたか、Botから見えない
+	ChannelStatusNotFound ChannelStatus = "not_found"
+)
+
+func (s ChannelStatus) Valid() bool {
+	switch s {
+	case ChannelStatusOK, ChannelStatusArchived, ChannelStatusNotFound:
+		return true
+	}
+	return false
+}
+
+// InitialVersion は作成直後のイベント・開催・タスクの版
+const InitialVersion = 1
+
 type Event struct {
-	ID   int    `db:"id" json:"id"`
-	Name string `db:"name" json:"name"`
+	ID            int           `db:"id" json:"id"`
+	Name          string        `db:"name" json:"name"`
+	CatchUpPolicy CatchUpPolicy `db:"catch_up_policy" json:"catchUpPolicy"`
+	BlockedPolicy BlockedPolicy `db:"blocked_policy" json:"blockedPolicy"`
+	// アーカイブした時刻（アーカイブしていなければnil）
+	ArchivedAt *time.Time `db:"archived_at" json:"archivedAt"`
+	// 楽観的排他制御のための版
+	Version int `db:"version" json:"version"`
+	// ゴミ箱に入れた時刻（ゴミ箱に無ければnil）
+	DeletedAt *time.Time `db:"deleted_at" json:"-"`
 }
 
 type Holding struct {
-	ID        int       `db:"id" json:"id"`
-	EventID   int       `db:"event_id" json:"eventId"`
-	Name      string    `db:"name" json:"name"`
-	Date      time.Time `db:"date" json:"date"`
-	ChannelID string    `db:"channel_id" json:"channelId"`
-	Mention   string    `db:"mention" json:"mention"`
+	ID      int       `db:"id" json:"id"`
+	EventID int       `db:"event_id" json:"eventId"`
+	Name    string    `db:"name" json:"name"`
+	Date    time.Time `db:"date" json:"date"`
+	// 開始時刻（"15:04:05"形式、未設定ならnil）
+	StartTime *string `db:"start_time" json:"startTime"`
+	// 複数日にわたる開催の最終日（1日のみの開催ならnil）
+	EndDate   *time.Time `db:"end_date" json:"endDate"`
+	ChannelID string     `db:"channel_id" json:"channelId"`
+	// 定期的な確認で分かったチャンネルの状態と確認した時刻
+	ChannelStatus    ChannelStatus `db:"channel_status" json:"channelStatus"`
+	ChannelCheckedAt *time.Time    `db:"channel_checked_at" json:"channelCheckedAt"`
+	// 旧形式の自由記述のメンション（Mentionsが空の場合のみ使う）
+	Mention string `db:"mention" json:"mention"`
+	// 楽観的排他制御のための版
+	Version int `db:"version" json:"version"`
+	// ゴミ箱に入れた時刻（ゴミ箱に無ければnil）
+	DeletedAt *time.Time `db:"deleted_at" json:"-"`
+	// メンションするユーザー・グループ（holding_mentionsから読み込む）
+	Mentions []HoldingMention `db:"-" json:"mentions"`
+}
+
+// MentionType はメンションする対象の種類を表す
+type MentionType string
+
+const (
+	MentionTypeUser  MentionType = "user"
+	MentionTypeGroup MentionType = "group"
+)
+
+func (t MentionType) Valid() bool {
+	switch t {
+	case MentionTypeUser, MentionTypeGroup:
+		return true
+	}
+	return false
+}
+
+// HoldingMention は開催のリマインドでメンションするtraQのユーザーまたはユーザーグループ
+type HoldingMention struct {
+	HoldingID int         `db:"holding_id" json:"-"`
+	Position  int         `db:"position" json:"-"`
+	Type      MentionType `db:"type" json:"type"`
+	// traQのユーザーまたはユーザーグループのUUID
+	TargetID string `db:"target_id" json:"id"`
+}
+
+// LastDate は開催の最終日を返す
+func (h Holding) LastDate() time.Time {
+	if h.EndDate != nil {
+		return *h.EndDate
+	}
+	return h.Date
+}
+
+// MultiDay は開催が複数日にわたるかを返す
+func (h Holding) MultiDay() bool {
+	return h.EndDate != nil && h.EndDate.After(h.Date)
 }
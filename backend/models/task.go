@@ -1,10 +1,152 @@
 package models
 
+import (
+	"time"
+)
+
+// TaskStatus はタスクのリマインド送信状態を表す
+//
+//	pending → sending → sent / failed
+//	pending → sending → skipped（作成時点で期限切れ）
+//	sending → pending（送信失敗時の再試行、またはat-least-onceでの復旧）
+type TaskStatus string
+
+const (
+	TaskStatusPending TaskStatus = "pending"
+	TaskStatusSending TaskStatus = "sending"
+	TaskStatusSent    TaskStatus = "sent"
+	TaskStatusFailed  TaskStatus = "failed"
+	TaskStatusSkipped TaskStatus = "skipped"
+)
+
+func (s TaskStatus) Valid() bool {
+	switch s {
+	case TaskStatusPending, TaskStatusSending, TaskStatusSent, TaskStatusFailed, TaskStatusSkipped:
+		return true
+	}
+	return false
+}
+
+// TaskAnchor はタスクのリマインド日時の基準となる開催の日を表す
+type TaskAnchor string
+
+const (
+	// 開催の初日（開始日時）
+	TaskAnchorStart TaskAnchor = "start"
+	// 開催の最終日
+	TaskAnchorEnd TaskAnchor = "end"
+)
+
+func (a TaskAnchor) Valid() bool {
+	switch a {
+	case TaskAnchorStart, TaskAnchorEnd:
+		return true
+	}
+	return false
+}
+
 type Task struct {
-	ID          int    `db:"id" json:"id"`
-	HoldingID   int    `db:"holding_id" json:"holdingId"`
-	Name        string `db:"name" json:"name"`
-	DaysBefore  int    `db:"days_before" json:"daysBefore"`
-	Description string `db:"description" json:"description"`
-	Reminded    bool   `db:"reminded"`
+	ID        int    `db:"id" json:"id"`
+	HoldingID int    `db:"holding_id" json:"holdingId"`
+	Name      string `db:"name" json:"name"`
+	// リマインド日時の基準となる開催の日
+	Anchor TaskAnchor `db:"anchor" json:"anchor"`
+	// 基準日の何日前か（負の場合は基準日の後）
+	DaysBefore int `db:"days_before" json:"daysBefore"`
+	// 基準日時からの分単位のオフセット（負の場合は前）。nilの場合はDaysBeforeとRemindTimeで決まる
+	OffsetMinutes *int `db:"offset_minutes" json:"offsetMinutes"`
+	// 日単位のタスクをリマインドする時刻（"15:04:05"形式）
+	RemindTime *string `db:"remind_time" json:"remindTime"`
+	// リマインド日時（TaskServiceが計算して保存する）
+	RemindAt    *time.Time `db:"remind_at" json:"remindAt"`
+	Description string     `db:"description" json:"description"`
+	Status      TaskStatus `db:"status" json:"status"`
+	Attempts    int        `db:"attempts" json:"-"`
+	LastError   *string    `db:"last_error" json:"-"`
+	SentAt      *time.Time `db:"sent_at" json:"sentAt"`
+	// 作成時刻（created_atの列を追加する前からあるタスクはnil）
+	CreatedAt *time.Time `db:"created_at" json:"createdAt"`
+	// タスクが完了した時刻（未完了ならnil）
+	CompletedAt *time.Time `db:"completed_at" json:"completedAt"`
+	// 旧フラグ（MigrateLegacyTaskStatusでStatusに移行済み）
+	Reminded bool `db:"reminded" json:"-"`
+	Skipped  bool `db:"skipped" json:"-"`
+	// リマインド送信のために確保しているインスタンスと確保時刻
+	ClaimedBy *string    `db:"claimed_by" json:"-"`
+	ClaimedAt *time.Time `db:"claimed_at" json:"-"`
+	// 送信1回ごとに発行され、送信結果の反映をその送信を確保したものに限定する
+	IdempotencyKey *string `db:"idempotency_key" json:"-"`
+	// 楽観的排他制御のための版（リマインドの送信状態の変化では増えない）
+	Version int `db:"version" json:"version"`
+	// ゴミ箱に入れた時刻（ゴミ箱に無ければnil）
+	DeletedAt *time.Time `db:"deleted_at" json:"-"`
+	// 前提タスクのID（task_dependenciesから読み込む）
+	DependsOn []int `db:"-" json:"dependsOn"`
+}
+
+// RemindDate はタスクのリマインド日を返す
+func (t Task) RemindDate(holding Holding) time.Time {
+	if t.RemindAt != nil {
+		y, m, d := t.RemindAt.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, holding.Date.Location())
+	}
+	return t.AnchorDate(holding).AddDate(0, 0, -t.DaysBefore)
+}
+
+// AnchorDate はリマインド日時の基準となる開催の日を返す
+func (t Task) AnchorDate(holding Holding) time.Time {
+	if t.Anchor == TaskAnchorEnd {
+		return holding.LastDate()
+	}
+	return holding.Date
+}
+
+// OverdueOnCreation はタスクの作成時点で既にリマインド日を過ぎていたかを返す
+// 分単位のオフセットを持つタスクはリマインド日時で比較する
+// 作成時刻が分からないタスクは期限切れではなかったものとして扱う
+func (t Task) OverdueOnCreation(holding Holding) bool {
+	if t.CreatedAt == nil {
+		return false
+	}
+	if t.OffsetMinutes != nil && t.RemindAt != nil {
+		return t.RemindAt.Before(*t.CreatedAt)
+	}
+	y, m, d := t.CreatedAt.Date()
+	createdDate := time.Date(y, m, d, 0, 0, 0, 0, holding.Date.Location())
+	return t.RemindDate(holding).Before(createdDate)
+}
+
+// Completed はタスクが完了済みかを返す
+func (t Task) Completed() bool {
+	return t.CompletedAt != nil
+}
+
+// ChecklistItem はタスクのチェックリストの項目
+type ChecklistItem struct {
+	ID       int    `db:"id" json:"id"`
+	TaskID   int    `db:"task_id" json:"taskId"`
+	Position int    `db:"position" json:"position"`
+	Text     string `db:"text" json:"text"`
+	// 完了した時刻（未完了ならnil）
+	DoneAt *time.Time `db:"done_at" json:"doneAt"`
+}
+
+func (i ChecklistItem) Done() bool {
+	return i.DoneAt != nil
+}
+
+// TaskComment はタスクへのコメント
+type TaskComment struct {
+	ID     int `db:"id" json:"id"`
+	TaskID int `db:"task_id" json:"taskId"`
+	// コメントしたtraQユーザーの名前
+	Author    string    `db:"author" json:"author"`
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Edited はコメントが作成後に編集されたかを返す
+func (c TaskComment) Edited() bool {
+	return c.UpdatedAt.After(c.CreatedAt)
 }
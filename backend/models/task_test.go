@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestTaskRemindDate(t *testing.T) {
+	holding := Holding{
+		Date:    time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+		EndDate: ptr(time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC)),
+	}
+	tests := []struct {
+		name string
+		task Task
+		want time.Time
+	}{
+		{
+			name: "days before the first day",
+			task: Task{Anchor: TaskAnchorStart, DaysBefore: 3},
+			want: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "days after the last day",
+			task: Task{Anchor: TaskAnchorEnd, DaysBefore: -2},
+			want: time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "remind_at takes precedence and drops the time of day",
+			task: Task{Anchor: TaskAnchorStart, DaysBefore: 3, RemindAt: ptr(time.Date(2024, 1, 19, 22, 30, 0, 0, time.UTC))},
+			want: time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.RemindDate(holding); !got.Equal(tt.want) {
+				t.Errorf("RemindDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskOverdueOnCreation(t *testing.T) {
+	holding := Holding{Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)}
+	remindAt := time.Date(2024, 1, 19, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		task Task
+		want bool
+	}{
+		{
+			name: "created before the remind date",
+			task: Task{DaysBefore: 1, RemindAt: &remindAt, CreatedAt: ptr(time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC))},
+			want: false,
+		},
+		{
+			name: "day task created on the remind date is not overdue",
+			task: Task{DaysBefore: 1, RemindAt: &remindAt, CreatedAt: ptr(time.Date(2024, 1, 19, 20, 0, 0, 0, time.UTC))},
+			want: false,
+		},
+		{
+			name: "day task created after the remind date",
+			task: Task{DaysBefore: 1, RemindAt: &remindAt, CreatedAt: ptr(time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC))},
+			want: true,
+		},
+		{
+			name: "minute offset task created after the remind time",
+			task: Task{OffsetMinutes: ptr(-360), RemindAt: &remindAt, CreatedAt: ptr(time.Date(2024, 1, 19, 20, 0, 0, 0, time.UTC))},
+			want: true,
+		},
+		{
+			name: "minute offset task created before the remind time",
+			task: Task{OffsetMinutes: ptr(-360), RemindAt: &remindAt, CreatedAt: ptr(time.Date(2024, 1, 19, 17, 0, 0, 0, time.UTC))},
+			want: false,
+		},
+		{
+			name: "unknown creation time",
+			task: Task{DaysBefore: 1, RemindAt: &remindAt},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.OverdueOnCreation(holding); got != tt.want {
+				t.Errorf("OverdueOnCreation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package openapi はAPIのOpenAPIドキュメントを提供し、リクエストをそれに照らして検証する
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/pirosiki197/event_reminder/problem"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+type Validator struct {
+	router routers.Router
+	json   []byte
+}
+
+func New() (*Validator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router, json: b}, nil
+}
+
+// ServeSpec はOpenAPIドキュメントをJSONで返す
+func (v *Validator) ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(v.json)
+}
+
+// Middleware はドキュメントに定義されたリクエストのパラメータとボディを検証し、
+// 不正な場合は400をproblem+jsonで返す。ドキュメントにないルートはそのまま後続に渡す
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				MultiError:         true,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			writeValidationError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var errs []error
+	var me openapi3.MultiError
+	if errors.As(err, &me) {
+		errs = me
+	} else {
+		errs = []error{err}
+	}
+
+	p := problem.New(http.StatusBadRequest, "request does not match the API schema")
+	for _, e := range errs {
+		p.InvalidParams = append(p.InvalidParams, describe(e)...)
+	}
+	problem.Write(w, r, p)
+}
+
+// describe は検証エラーをどの項目がなぜ不正かに分解する
+// リクエストボディの場合は不正な項目ごとに分ける
+func describe(err error) []problem.InvalidParam {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return []problem.InvalidParam{{Reason: err.Error()}}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if reqErr.Parameter != nil {
+		param := problem.InvalidParam{Name: reqErr.Parameter.Name, Reason: reqErr.Reason}
+		if errors.As(reqErr.Err, &schemaErr) {
+			param.Reason = schemaErr.Reason
+		} else if param.Reason == "" && reqErr.Err != nil {
+			param.Reason = reqErr.Err.Error()
+		}
+		return []problem.InvalidParam{param}
+	}
+	if errors.As(reqErr.Err, &schemaErr) {
+		return describeSchema(nil, reqErr.Err)
+	}
+
+	reason := reqErr.Reason
+	if reason == "" && reqErr.Err != nil {
+		reason = reqErr.Err.Error()
+	}
+	return []problem.InvalidParam{{Reason: reason}}
+}
+
+// describeSchema はスキーマの検証エラーを末端の項目ごとに分解する
+// allOfの中のエラーの位置はallOfを持つ項目からの相対位置なので、prefixにその位置を渡す
+func describeSchema(prefix []string, err error) []problem.InvalidParam {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var params []problem.InvalidParam
+		for _, inner := range e {
+			params = append(params, describeSchema(prefix, inner)...)
+		}
+		return params
+	case *openapi3.SchemaError:
+		path := append(slices.Clone(prefix), e.JSONPointer()...)
+		if e.SchemaField == "allOf" && e.Origin != nil {
+			return describeSchema(path, e.Origin)
+		}
+		return []problem.InvalidParam{{Name: strings.Join(path, "."), Reason: e.Reason}}
+	}
+	if inner := errors.Unwrap(err); inner != nil {
+		return describeSchema(prefix, inner)
+	}
+	return []problem.InvalidParam{{Name: strings.Join(prefix, "."), Reason: err.Error()}}
+}
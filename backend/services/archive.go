@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// イベントのアーカイブ
+// ========================================
+
+// ArchiveEvent はイベントをアーカイブし、開催とタスクごと既定の一覧とリマインドの対象外にする
+// 既にアーカイブしている場合はアーカイブした時刻を維持する
+func (s *TaskService) ArchiveEvent(ctx context.Context, id int) (models.Event, error) {
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE `events` SET `version` = IF(`archived_at` IS NULL, `version` + 1, `version`), `archived_at` = COALESCE(`archived_at`, ?) WHERE `id` = ? AND `deleted_at` IS NULL",
+		time.Now(), id,
+	)
+	if err != nil {
+		s.logger.Error("failed to archive event", slog.String("err", err.Error()))
+		return models.Event{}, err
+	}
+	if err := requireAffected(result, "event", id); err != nil {
+		return models.Event{}, err
+	}
+	return s.GetEventByID(ctx, id)
+}
+
+// UnarchiveEvent はイベントのアーカイブを解除する
+// アーカイブ中にリマインド日時を迎えたタスクは、解除した時点でまとめて送られないようにスキップ扱いにする
+func (s *TaskService) UnarchiveEvent(ctx context.Context, id int) (models.Event, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Event{}, err
+	}
+	defer tx.Rollback()
+
+	var event models.Event
+	err = tx.GetContext(ctx, &event, "SELECT * FROM `events` WHERE `id` = ? AND `deleted_at` IS NULL FOR UPDATE", id)
+	if err != nil {
+		return models.Event{}, notFound(err, "event", id)
+	}
+	if event.ArchivedAt == nil {
+		return event, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `tasks` t INNER JOIN `holdings` h ON t.`holding_id` = h.`id` SET t.`status` = 'skipped'"+
+			" WHERE h.`event_id` = ? AND t.`status` = 'pending' AND t.`remind_at` >= ? AND t.`remind_at` <= ?",
+		id, event.ArchivedAt, time.Now(),
+	)
+	if err != nil {
+		s.logger.Error("failed to skip tasks of archived event", slog.String("err", err.Error()))
+		return models.Event{}, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE `events` SET `archived_at` = NULL, `version` = `version` + 1 WHERE `id` = ?", id); err != nil {
+		s.logger.Error("failed to unarchive event", slog.String("err", err.Error()))
+		return models.Event{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Event{}, err
+	}
+	event.ArchivedAt = nil
+	event.Version++
+	return event, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// 開催のタスクの一括操作
+// ========================================
+
+// TaskBatch は開催のタスクに対してまとめて行う操作
+// 削除、更新、作成、日数のずらしの順に適用する
+type TaskBatch struct {
+	Create []models.Task
+	// IDで対象を指定し、部分更新を適用済みのタスク
+	Update []models.Task
+	Delete []int
+	// 全てのタスクのリマインド日をこの日数だけ前にずらす（負の場合は後ろ）
+	ShiftDays int
+}
+
+// ApplyTaskBatch は開催のタスクへの操作を1つのトランザクションで適用する
+// 不正な項目が1つでもあれば何も変更せず、全ての不正な項目をBatchValidationErrorで返す
+// 作成したタスクのIDをCreateと同じ順序で返す
+func (s *TaskService) ApplyTaskBatch(ctx context.Context, holdingID int, batch TaskBatch) ([]int, error) {
+	var batchErr BatchValidationError
+	for i, task := range batch.Create {
+		batchErr.Add(fmt.Sprintf("create.%d", i), validateTask(task))
+	}
+	for i, task := range batch.Update {
+		batchErr.Add(fmt.Sprintf("update.%d", i), validateTask(task))
+	}
+	if err := batchErr.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// 開催のタスクをロックして、操作の途中で他の更新が入らないようにする
+	var holdingIDs []int
+	if err := tx.SelectContext(ctx, &holdingIDs, "SELECT `id` FROM `holdings` WHERE `id` = ? AND `deleted_at` IS NULL FOR UPDATE", holdingID); err != nil {
+		return nil, err
+	}
+	if len(holdingIDs) == 0 {
+		return nil, fmt.Errorf("holding %d: %w", holdingID, ErrNotFound)
+	}
+	var taskIDs []int
+	if err := tx.SelectContext(ctx, &taskIDs, "SELECT `id` FROM `tasks` WHERE `holding_id` = ? AND `deleted_at` IS NULL FOR UPDATE", holdingID); err != nil {
+		return nil, err
+	}
+	owned := make(map[int]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		owned[id] = true
+	}
+
+	deleted := make(map[int]bool, len(batch.Delete))
+	for i, id := range batch.Delete {
+		if !owned[id] {
+			batchErr.Add(fmt.Sprintf("delete.%d", i), newValidationError("", fmt.Sprintf("task %d does not belong to this holding", id)))
+		}
+		deleted[id] = true
+	}
+	for i, task := range batch.Update {
+		path := fmt.Sprintf("update.%d", i)
+		switch {
+		case !owned[task.ID]:
+			batchErr.Add(path, newValidationError("id", fmt.Sprintf("task %d does not belong to this holding", task.ID)))
+		case deleted[task.ID]:
+			batchErr.Add(path, newValidationError("id", fmt.Sprintf("task %d is also deleted", task.ID)))
+		}
+	}
+	if err := batchErr.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(batch.Delete) > 0 {
+		// 個別の削除と同じくゴミ箱に入れる
+		query, args, err := sqlx.In("UPDATE `tasks` SET `deleted_at` = ? WHERE `id` IN (?)", trashTime(), batch.Delete)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			s.logger.Error("failed to delete tasks", slog.String("err", err.Error()))
+			return nil, err
+		}
+	}
+
+	// 前提タスクの循環などは適用してみるまで分からないので、不正な項目を集めてから最後にまとめて返す
+	for i, task := range batch.Update {
+		if err := s.updateTask(ctx, tx, task.ID, task); err != nil {
+			if !batchErr.Add(fmt.Sprintf("update.%d", i), err) {
+				return nil, err
+			}
+		}
+	}
+
+	created := make([]int, len(batch.Create))
+	for i, task := range batch.Create {
+		task.HoldingID = holdingID
+		id, err := s.insertTask(ctx, tx, task)
+		if err != nil {
+			if !batchErr.Add(fmt.Sprintf("create.%d", i), err) {
+				return nil, err
+			}
+			continue
+		}
+		created[i] = id
+	}
+
+	if batch.ShiftDays != 0 {
+		if err := s.shiftTaskDays(ctx, tx, holdingID, batch.ShiftDays); err != nil {
+			if !batchErr.Add("shiftDays", err) {
+				return nil, err
+			}
+		}
+	}
+
+	if err := batchErr.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// shiftTaskDays は開催の全てのタスクのリマインド日をdays日だけ前にずらす
+// 分単位のオフセットを持つタスクはオフセットを同じ日数分ずらす
+func (s *TaskService) shiftTaskDays(ctx context.Context, tx *sqlx.Tx, holdingID int, days int) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE `tasks` SET `days_before` = `days_before` + ?, `offset_minutes` = `offset_minutes` - ?, `version` = `version` + 1 WHERE `holding_id` = ? AND `deleted_at` IS NULL",
+		days, days*24*60, holdingID,
+	)
+	if err != nil {
+		s.logger.Error("failed to shift tasks", slog.String("err", err.Error()))
+		return err
+	}
+
+	if err := refreshRemindAt(ctx, tx, "t.`holding_id` = ?", holdingID); err != nil {
+		s.logger.Error("failed to compute remind_at", slog.String("err", err.Error()))
+		return err
+	}
+
+	// ずらした結果が範囲外になるタスクがあれば全体を取り消す
+	var tasks []models.Task
+	if err := tx.SelectContext(ctx, &tasks, "SELECT * FROM `tasks` WHERE `holding_id` = ? AND `deleted_at` IS NULL", holdingID); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := validateTask(task); err != nil {
+			return newValidationError("", fmt.Sprintf("task %d would be out of range: %s", task.ID, err.Error()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// 通知先チャンネルの定期的な確認
+// ========================================
+
+// 開催の通知先チャンネルがアーカイブ・削除されていないかを確認する間隔
+const channelCheckInterval = time.Hour
+
+// channelCheckCond は通知先チャンネルを確認する開催（これから通知する可能性のある開催）の条件
+const channelCheckCond = "`deleted_at` IS NULL AND COALESCE(`end_date`, `date`) >= ?" +
+	" AND `event_id` IN (SELECT `id` FROM `events` WHERE `deleted_at` IS NULL AND `archived_at` IS NULL)"
+
+// GetChannelsToCheck は確認が必要な通知先チャンネルのIDを返す
+func (s *TaskService) GetChannelsToCheck(ctx context.Context) ([]string, error) {
+	var channelIDs []string
+	err := s.db.SelectContext(ctx, &channelIDs,
+		"SELECT DISTINCT `channel_id` FROM `holdings` WHERE "+channelCheckCond, today(),
+	)
+	return channelIDs, err
+}
+
+// UpdateChannelStatus はチャンネルに通知する開催にチャンネルの状態を記録し、状態が変わった開催の数を返す
+func (s *TaskService) UpdateChannelStatus(ctx context.Context, channelID string, status models.ChannelStatus, checkedAt time.Time) (int64, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE `holdings` SET `channel_status` = ? WHERE `channel_id` = ? AND `channel_status` != ? AND "+channelCheckCond,
+		status, channelID, status, today(),
+	)
+	if err != nil {
+		s.logger.Error("failed to update channel status", slog.String("err", err.Error()))
+		return 0, err
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `holdings` SET `channel_checked_at` = ? WHERE `channel_id` = ? AND "+channelCheckCond,
+		checkedAt, channelID, today(),
+	)
+	if err != nil {
+		s.logger.Error("failed to update channel checked time", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	return changed, tx.Commit()
+}
+
+// checkChannels は開催の通知先チャンネルがアーカイブ・削除されていないかを確認し、開催に記録する
+func (rs *RemindService) checkChannels() {
+	channelIDs, err := rs.taskSvc.GetChannelsToCheck(rs.ctx)
+	if err != nil {
+		rs.logger.Error("failed to get channels to check", slog.String("err", err.Error()))
+		return
+	}
+
+	for _, channelID := range channelIDs {
+		status, err := rs.traqSvc.GetChannelStatus(rs.ctx, channelID)
+		if err != nil {
+			// traQに繋がらない場合は前回の結果を残す
+			rs.logger.Error("failed to get channel status", slog.String("channel_id", channelID), slog.String("err", err.Error()))
+			continue
+		}
+		changed, err := rs.taskSvc.UpdateChannelStatus(rs.ctx, channelID, status, time.Now())
+		if err != nil {
+			continue
+		}
+		if changed > 0 && status != models.ChannelStatusOK {
+			rs.logger.Warn("channel of holdings is no longer available",
+				slog.String("channel_id", channelID),
+				slog.String("status", string(status)),
+				slog.Int64("holdings", changed),
+			)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// タスクのチェックリスト
+// ========================================
+
+func validateChecklistItem(item models.ChecklistItem) error {
+	if item.Text == "" {
+		return newValidationError("text", "checklist item text is required")
+	}
+	if len([]rune(item.Text)) > 255 {
+		return newValidationError("text", "must be at most 255 characters")
+	}
+	return nil
+}
+
+// checklistMap は各タスクのチェックリストを並び順で取得する
+func checklistMap(ctx context.Context, q sqlx.QueryerContext, taskIDs []int) (map[int][]models.ChecklistItem, error) {
+	items := make(map[int][]models.ChecklistItem, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return items, nil
+	}
+	query, args, err := sqlx.In(
+		"SELECT * FROM `task_checklist_items` WHERE `task_id` IN (?) ORDER BY `task_id`, `position`",
+		taskIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var rows []models.ChecklistItem
+	if err := sqlx.SelectContext(ctx, q, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		items[row.TaskID] = append(items[row.TaskID], row)
+	}
+	return items, nil
+}
+
+func (s *TaskService) GetChecklistItems(ctx context.Context, taskID int) ([]models.ChecklistItem, error) {
+	// 存在しないタスクと項目のないタスクを区別する
+	if _, err := s.GetTaskByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	items, err := checklistMap(ctx, s.db, []int{taskID})
+	if err != nil {
+		return nil, err
+	}
+	if items[taskID] == nil {
+		return []models.ChecklistItem{}, nil
+	}
+	return items[taskID], nil
+}
+
+// GetChecklistItemsByTaskIDs は複数のタスクのチェックリストをまとめて取得する
+func (s *TaskService) GetChecklistItemsByTaskIDs(ctx context.Context, taskIDs []int) (map[int][]models.ChecklistItem, error) {
+	return checklistMap(ctx, s.db, taskIDs)
+}
+
+// selectLiveChecklistItem はゴミ箱に入っていないタスク（と開催・イベント）の項目を取得するクエリ
+// ゴミ箱にあるタスクの項目は一覧にも出ないため、IDを指定しても存在しないものとして扱う
+const selectLiveChecklistItem = "SELECT i.* FROM `task_checklist_items` i" +
+	" INNER JOIN `tasks` t ON t.`id` = i.`task_id` AND t.`deleted_at` IS NULL" +
+	" INNER JOIN `holdings` h ON h.`id` = t.`holding_id` AND h.`deleted_at` IS NULL" +
+	" INNER JOIN `events` e ON e.`id` = h.`event_id` AND e.`deleted_at` IS NULL" +
+	" WHERE i.`id` = ?"
+
+func (s *TaskService) GetChecklistItemByID(ctx context.Context, id int) (models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	err := s.db.GetContext(ctx, &item, selectLiveChecklistItem, id)
+	return item, notFound(err, "checklist item", id)
+}
+
+// CreateChecklistItem はチェックリストの末尾に項目を追加する
+func (s *TaskService) CreateChecklistItem(ctx context.Context, item models.ChecklistItem) (int, error) {
+	if err := validateChecklistItem(item); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// 同じタスクへの同時追加で位置が重ならないようにタスクの行をロックする
+	var taskID int
+	err = tx.GetContext(ctx, &taskID, "SELECT `id` FROM `tasks` WHERE `id` = ? AND `deleted_at` IS NULL FOR UPDATE", item.TaskID)
+	if err != nil {
+		return 0, notFound(err, "task", item.TaskID)
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO `task_checklist_items` (`task_id`, `position`, `text`, `done_at`) "+
+			"SELECT ?, COALESCE(MAX(`position`) + 1, 0), ?, ? FROM `task_checklist_items` WHERE `task_id` = ?",
+		item.TaskID,
+		item.Text,
+		item.DoneAt,
+		item.TaskID,
+	)
+	if err != nil {
+		s.logger.Error("failed to create checklist item", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// UpdateChecklistItem は項目の内容と完了状態を更新し、位置が変わった場合は並べ替える
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, id int, item models.ChecklistItem) error {
+	if err := validateChecklistItem(item); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existing models.ChecklistItem
+	if err := tx.GetContext(ctx, &existing, selectLiveChecklistItem+" FOR UPDATE OF i", id); err != nil {
+		return notFound(err, "checklist item", id)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `task_checklist_items` SET `text` = ?, `done_at` = ? WHERE `id` = ?",
+		item.Text,
+		item.DoneAt,
+		id,
+	)
+	if err != nil {
+		s.logger.Error("failed to update checklist item", slog.String("err", err.Error()))
+		return err
+	}
+
+	if err := moveChecklistItem(ctx, tx, existing.TaskID, id, item.Position); err != nil {
+		s.logger.Error("failed to reorder checklist", slog.String("err", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// moveChecklistItem は項目をpositionの位置に移動し、チェックリスト全体の位置を0から振り直す
+// positionが範囲外の場合は先頭または末尾に移動する
+func moveChecklistItem(ctx context.Context, tx *sqlx.Tx, taskID int, id int, position int) error {
+	var ids []int
+	err := tx.SelectContext(ctx, &ids,
+		"SELECT `id` FROM `task_checklist_items` WHERE `task_id` = ? ORDER BY `position`, `id` FOR UPDATE",
+		taskID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, itemID := range reorderedIDs(ids, id, position) {
+		_, err := tx.ExecContext(ctx, "UPDATE `task_checklist_items` SET `position` = ? WHERE `id` = ?", i, itemID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reorderedIDs はidsの並びでidをpositionの位置に移した並びを返す
+// positionが範囲外の場合は先頭または末尾に移す
+func reorderedIDs(ids []int, id int, position int) []int {
+	ordered := make([]int, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			ordered = append(ordered, other)
+		}
+	}
+	position = max(0, min(position, len(ordered)))
+	return slices.Insert(ordered, position, id)
+}
+
+// DeleteChecklistItem は項目を削除し、後ろの項目の位置を詰める
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var item models.ChecklistItem
+	err = tx.GetContext(ctx, &item, selectLiveChecklistItem+" FOR UPDATE OF i", id)
+	if err != nil {
+		return notFound(err, "checklist item", id)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `task_checklist_items` WHERE `id` = ?", id); err != nil {
+		s.logger.Error("failed to delete checklist item", slog.String("err", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `task_checklist_items` SET `position` = `position` - 1 WHERE `task_id` = ? AND `position` > ?",
+		item.TaskID, item.Position,
+	)
+	if err != nil {
+		s.logger.Error("failed to reorder checklist", slog.String("err", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// copyChecklistItems はコピー元のタスクのチェックリストを、未完了の状態でコピー先のタスクに複製する
+// idMapはコピー元のタスクIDからコピー先のタスクIDへの対応
+func copyChecklistItems(ctx context.Context, tx *sqlx.Tx, idMap map[int]int) error {
+	srcIDs := make([]int, 0, len(idMap))
+	for id := range idMap {
+		srcIDs = append(srcIDs, id)
+	}
+	items, err := checklistMap(ctx, tx, srcIDs)
+	if err != nil {
+		return err
+	}
+	for src, srcItems := range items {
+		for _, item := range srcItems {
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO `task_checklist_items` (`task_id`, `position`, `text`) VALUES (?, ?, ?)",
+				idMap[src], item.Position, item.Text,
+			)
+			if err != nil {
+				return fmt.Errorf("copy checklist item %d: %w", item.ID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReorderedIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []int
+		id       int
+		position int
+		want     []int
+	}{
+		{
+			name:     "move to front",
+			ids:      []int{1, 2, 3},
+			id:       3,
+			position: 0,
+			want:     []int{3, 1, 2},
+		},
+		{
+			name:     "move to middle",
+			ids:      []int{1, 2, 3, 4},
+			id:       1,
+			position: 2,
+			want:     []int{2, 3, 1, 4},
+		},
+		{
+			name:     "keep position",
+			ids:      []int{1, 2, 3},
+			id:       2,
+			position: 1,
+			want:     []int{1, 2, 3},
+		},
+		{
+			name:     "negative position moves to front",
+			ids:      []int{1, 2, 3},
+			id:       2,
+			position: -5,
+			want:     []int{2, 1, 3},
+		},
+		{
+			name:     "position past the end moves to back",
+			ids:      []int{1, 2, 3},
+			id:       1,
+			position: 10,
+			want:     []int{2, 3, 1},
+		},
+		{
+			name:     "position equal to length moves to back",
+			ids:      []int{1, 2, 3},
+			id:       2,
+			position: 3,
+			want:     []int{1, 3, 2},
+		},
+		{
+			name:     "only item",
+			ids:      []int{1},
+			id:       1,
+			position: 4,
+			want:     []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reorderedIDs(tt.ids, tt.id, tt.position)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("reorderedIDs(%v, %d, %d) = %v, want %v", tt.ids, tt.id, tt.position, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// タスクへのコメント
+// ========================================
+
+const (
+	maxCommentAuthorLength = 32
+	// traQのメッセージの最大長に合わせる（コメントをチャンネルに転記するため）
+	maxCommentBodyLength = 10000
+)
+
+func validateComment(comment models.TaskComment) error {
+	if comment.Author == "" {
+		return newValidationError("author", "comment author is required")
+	}
+	if len([]rune(comment.Author)) > maxCommentAuthorLength {
+		return newValidationError("author", fmt.Sprintf("must be at most %d characters", maxCommentAuthorLength))
+	}
+	if comment.Body == "" {
+		return newValidationError("body", "comment body is required")
+	}
+	if len([]rune(comment.Body)) > maxCommentBodyLength {
+		return newValidationError("body", fmt.Sprintf("must be at most %d characters", maxCommentBodyLength))
+	}
+	return nil
+}
+
+// GetComments はタスクへのコメントを古い順に取得する
+func (s *TaskService) GetComments(ctx context.Context, taskID int) ([]models.TaskComment, error) {
+	// 存在しないタスクとコメントのないタスクを区別する
+	if _, err := s.GetTaskByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	comments := []models.TaskComment{}
+	err := s.db.SelectContext(ctx, &comments,
+		"SELECT * FROM `task_comments` WHERE `task_id` = ? ORDER BY `created_at`, `id`",
+		taskID,
+	)
+	return comments, err
+}
+
+func (s *TaskService) GetCommentByID(ctx context.Context, id int) (models.TaskComment, error) {
+	var comment models.TaskComment
+	err := s.db.GetContext(ctx, &comment, "SELECT * FROM `task_comments` WHERE `id` = ?", id)
+	return comment, notFound(err, "comment", id)
+}
+
+func (s *TaskService) CreateComment(ctx context.Context, comment models.TaskComment) (int, error) {
+	if err := validateComment(comment); err != nil {
+		return 0, err
+	}
+
+	// ゴミ箱にあるタスクにはコメントできない
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO `task_comments` (`task_id`, `author`, `body`) SELECT ?, ?, ? FROM `tasks` WHERE `id` = ? AND `deleted_at` IS NULL",
+		comment.TaskID,
+		comment.Author,
+		comment.Body,
+		comment.TaskID,
+	)
+	if err != nil {
+		s.logger.Error("failed to create comment", slog.String("err", err.Error()))
+		return 0, err
+	}
+	if err := requireAffected(result, "task", comment.TaskID); err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// UpdateComment はコメントの本文を更新する
+// actorがコメントした本人でなければErrForbiddenを返す
+func (s *TaskService) UpdateComment(ctx context.Context, id int, actor string, body string) error {
+	comment, err := s.GetCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeComment(comment, actor); err != nil {
+		return err
+	}
+	comment.Body = body
+	if err := validateComment(comment); err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE `task_comments` SET `body` = ? WHERE `id` = ?",
+		body,
+		id,
+	)
+	if err != nil {
+		s.logger.Error("failed to update comment", slog.String("err", err.Error()))
+		return err
+	}
+	return requireAffected(result, "comment", id)
+}
+
+// DeleteComment はコメントを削除する
+// actorがコメントした本人でなければErrForbiddenを返す
+func (s *TaskService) DeleteComment(ctx context.Context, id int, actor string) error {
+	comment, err := s.GetCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeComment(comment, actor); err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM `task_comments` WHERE `id` = ?", id)
+	if err != nil {
+		s.logger.Error("failed to delete comment", slog.String("err", err.Error()))
+		return err
+	}
+	return requireAffected(result, "comment", id)
+}
+
+func authorizeComment(comment models.TaskComment, actor string) error {
+	if actor != comment.Author {
+		return fmt.Errorf("comment %d is written by another user: %w", comment.ID, ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// 締め切り一覧（ダッシュボード用）
+// ========================================
+
+// DeadlineFilter は締め切り一覧の絞り込み条件
+// From, To, Whenはリマインド日に対する条件
+type DeadlineFilter struct {
+	EventID   *int
+	ChannelID string
+	From      *time.Time
+	To        *time.Time
+	When      string
+}
+
+// Deadline はリマインド日を計算済みのタスク
+type Deadline struct {
+	Task      models.Task
+	Holding   models.Holding
+	EventName string
+
+	RemindDate time.Time
+	// 今日からリマインド日までの日数（過ぎている場合は負）
+	DaysRemaining int
+	// リマインド日を過ぎていて未完了
+	Overdue bool
+}
+
+// DeadlineWeek は同じ週（月曜始まり）にリマインド日がある締め切りのまとまり
+type DeadlineWeek struct {
+	WeekStart time.Time
+	Deadlines []Deadline
+}
+
+type deadlineRow struct {
+	models.Task
+	Holding   models.Holding `db:"holding"`
+	EventName string         `db:"event_name"`
+}
+
+// ListDeadlines は開催をまたいでタスクをリマインド日順に取得し、週ごとにまとめる
+func (s *TaskService) ListDeadlines(ctx context.Context, filter DeadlineFilter) ([]DeadlineWeek, error) {
+	var q listQuery
+	// アーカイブしたイベントのタスクはリマインドされないので含めない
+	q.where("t.`deleted_at` IS NULL AND e.`archived_at` IS NULL")
+	if filter.EventID != nil {
+		q.where("h.`event_id` = ?", *filter.EventID)
+	}
+	if filter.ChannelID != "" {
+		q.where("h.`channel_id` = ?", filter.ChannelID)
+	}
+	if filter.From != nil {
+		q.where(remindDateExpr+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q.where(remindDateExpr+" <= ?", *filter.To)
+	}
+	if err := applyWhen(&q, filter.When, remindDateExpr); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT t.*, " +
+		"h.`id` AS `holding.id`, h.`event_id` AS `holding.event_id`, h.`name` AS `holding.name`, " +
+		"h.`date` AS `holding.date`, h.`start_time` AS `holding.start_time`, h.`end_date` AS `holding.end_date`, h.`channel_id` AS `holding.channel_id`, h.`mention` AS `holding.mention`, " +
+		"e.`name` AS `event_name` " +
+		"FROM `tasks` t " +
+		"INNER JOIN `holdings` h ON t.`holding_id` = h.`id` " +
+		"INNER JOIN `events` e ON h.`event_id` = e.`id` " +
+		q.whereClause() + " ORDER BY t.`remind_at` ASC, t.`id` ASC"
+
+	var rows []deadlineRow
+	if err := s.db.SelectContext(ctx, &rows, query, q.args...); err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	deps, err := dependencyMap(ctx, s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].DependsOn = deps[rows[i].ID]
+	}
+
+	return groupDeadlinesByWeek(rows, today()), nil
+}
+
+// groupDeadlinesByWeek はリマインド日順に並んだ行を週ごとにまとめる
+func groupDeadlinesByWeek(rows []deadlineRow, today time.Time) []DeadlineWeek {
+	weeks := []DeadlineWeek{}
+	for _, row := range rows {
+		remindDate := row.Task.RemindDate(row.Holding)
+		deadline := Deadline{
+			Task:          row.Task,
+			Holding:       row.Holding,
+			EventName:     row.EventName,
+			RemindDate:    remindDate,
+			DaysRemaining: daysBetween(today, remindDate),
+			Overdue:       remindDate.Before(today) && !row.Task.Completed(),
+		}
+
+		weekStart := startOfWeek(remindDate)
+		if len(weeks) == 0 || !weeks[len(weeks)-1].WeekStart.Equal(weekStart) {
+			weeks = append(weeks, DeadlineWeek{WeekStart: weekStart})
+		}
+		last := &weeks[len(weeks)-1]
+		last.Deadlines = append(last.Deadlines, deadline)
+	}
+	return weeks
+}
+
+// startOfWeek はその日を含む週の月曜日を返す
+func startOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return date.AddDate(0, 0, -offset)
+}
+
+// daysBetween はfromからtoまでの日数を返す（時刻は無視する）
+func daysBetween(from, to time.Time) int {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	f := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	t := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	return int(t.Sub(f).Hours() / 24)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStartOfWeek(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want time.Time
+	}{
+		{name: "monday", date: date(2024, 1, 1), want: date(2024, 1, 1)},
+		{name: "wednesday", date: date(2024, 1, 3), want: date(2024, 1, 1)},
+		{name: "sunday belongs to the previous monday", date: date(2024, 1, 7), want: date(2024, 1, 1)},
+		{name: "across a month boundary", date: date(2024, 3, 2), want: date(2024, 2, 26)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startOfWeek(tt.date); !got.Equal(tt.want) {
+				t.Errorf("startOfWeek(%s) = %s, want %s", tt.date.Format(time.DateOnly), got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{name: "same day", from: date(2024, 1, 1), to: date(2024, 1, 1), want: 0},
+		{name: "future", from: date(2024, 1, 1), to: date(2024, 1, 11), want: 10},
+		{name: "past", from: date(2024, 1, 4), to: date(2024, 1, 1), want: -3},
+		{name: "leap day", from: date(2024, 2, 28), to: date(2024, 3, 1), want: 2},
+		{
+			name: "time of day is ignored",
+			from: time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC),
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("daysBetween() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupDeadlinesByWeek(t *testing.T) {
+	holding := models.Holding{ID: 1, Date: date(2024, 1, 20)}
+	row := func(id int, remindDate time.Time, completed bool) deadlineRow {
+		remindAt := remindDate.Add(8 * time.Hour)
+		task := models.Task{ID: id, HoldingID: holding.ID, RemindAt: &remindAt}
+		if completed {
+			task.CompletedAt = &remindAt
+		}
+		return deadlineRow{Task: task, Holding: holding, EventName: "event"}
+	}
+	rows := []deadlineRow{
+		row(1, date(2024, 1, 1), true),
+		row(2, date(2024, 1, 2), false),
+		row(3, date(2024, 1, 7), false),
+		row(4, date(2024, 1, 8), false),
+	}
+	today := date(2024, 1, 3)
+
+	weeks := groupDeadlinesByWeek(rows, today)
+
+	if len(weeks) != 2 {
+		t.Fatalf("got %d weeks, want 2", len(weeks))
+	}
+	wantWeeks := []struct {
+		start time.Time
+		ids   []int
+	}{
+		{start: date(2024, 1, 1), ids: []int{1, 2, 3}},
+		{start: date(2024, 1, 8), ids: []int{4}},
+	}
+	for i, want := range wantWeeks {
+		if !weeks[i].WeekStart.Equal(want.start) {
+			t.Errorf("weeks[%d].WeekStart = %s, want %s", i, weeks[i].WeekStart.Format(time.DateOnly), want.start.Format(time.DateOnly))
+		}
+		if len(weeks[i].Deadlines) != len(want.ids) {
+			t.Fatalf("weeks[%d] has %d deadlines, want %d", i, len(weeks[i].Deadlines), len(want.ids))
+		}
+		for j, id := range want.ids {
+			if got := weeks[i].Deadlines[j].Task.ID; got != id {
+				t.Errorf("weeks[%d].Deadlines[%d] is task %d, want %d", i, j, got, id)
+			}
+		}
+	}
+
+	tests := []struct {
+		deadline      Deadline
+		daysRemaining int
+		overdue       bool
+	}{
+		{deadline: weeks[0].Deadlines[0], daysRemaining: -2, overdue: false},
+		{deadline: weeks[0].Deadlines[1], daysRemaining: -1, overdue: true},
+		{deadline: weeks[0].Deadlines[2], daysRemaining: 4, overdue: false},
+		{deadline: weeks[1].Deadlines[0], daysRemaining: 5, overdue: false},
+	}
+	for _, tt := range tests {
+		if tt.deadline.DaysRemaining != tt.daysRemaining {
+			t.Errorf("task %d: DaysRemaining = %d, want %d", tt.deadline.Task.ID, tt.deadline.DaysRemaining, tt.daysRemaining)
+		}
+		if tt.deadline.Overdue != tt.overdue {
+			t.Errorf("task %d: Overdue = %v, want %v", tt.deadline.Task.ID, tt.deadline.Overdue, tt.overdue)
+		}
+	}
+}
+
+func TestGroupDeadlinesByWeekEmpty(t *testing.T) {
+	weeks := groupDeadlinesByWeek(nil, date(2024, 1, 1))
+	if weeks == nil || len(weeks) != 0 {
+		t.Errorf("groupDeadlinesByWeek(nil) = %#v, want empty non-nil slice", weeks)
+	}
+}
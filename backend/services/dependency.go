@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// タスクの前提タスク（task_dependencies）
+// ========================================
+
+type taskDependency struct {
+	TaskID          int `db:"task_id"`
+	DependsOnTaskID int `db:"depends_on_task_id"`
+}
+
+// dependencyMap は各タスクの前提タスクのIDを取得する（ゴミ箱にある前提タスクは除く）
+func dependencyMap(ctx context.Context, q sqlx.QueryerContext, taskIDs []int) (map[int][]int, error) {
+	deps := make(map[int][]int, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return deps, nil
+	}
+	query, args, err := sqlx.In(
+		"SELECT d.* FROM `task_dependencies` d INNER JOIN `tasks` p ON d.`depends_on_task_id` = p.`id`"+
+			" WHERE d.`task_id` IN (?) AND p.`deleted_at` IS NULL ORDER BY d.`task_id`, d.`depends_on_task_id`",
+		taskIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var rows []taskDependency
+	if err := sqlx.SelectContext(ctx, q, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		deps[row.TaskID] = append(deps[row.TaskID], row.DependsOnTaskID)
+	}
+	return deps, nil
+}
+
+// attachDependencies はタスクのDependsOnを埋める
+func (s *TaskService) attachDependencies(ctx context.Context, tasks []models.Task) error {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	deps, err := dependencyMap(ctx, s.db, ids)
+	if err != nil {
+		s.logger.Error("failed to get task dependencies", slog.String("err", err.Error()))
+		return err
+	}
+	for i := range tasks {
+		tasks[i].DependsOn = deps[tasks[i].ID]
+		if tasks[i].DependsOn == nil {
+			tasks[i].DependsOn = []int{}
+		}
+	}
+	return nil
+}
+
+// setDependencies はタスクの前提タスクをtask.DependsOnで置き換える
+// 前提タスクは同じ開催のタスクに限り、循環する指定はValidationErrorになる
+func setDependencies(ctx context.Context, tx *sqlx.Tx, task models.Task) error {
+	dependsOn := slices.Clone(task.DependsOn)
+	slices.Sort(dependsOn)
+	dependsOn = slices.Compact(dependsOn)
+
+	if slices.Contains(dependsOn, task.ID) {
+		return newValidationError("dependsOn", "a task cannot depend on itself")
+	}
+
+	if len(dependsOn) > 0 {
+		query, args, err := sqlx.In(
+			"SELECT COUNT(*) FROM `tasks` WHERE `holding_id` = ? AND `id` IN (?) AND `deleted_at` IS NULL",
+			task.HoldingID, dependsOn,
+		)
+		if err != nil {
+			return err
+		}
+		var count int
+		if err := tx.GetContext(ctx, &count, query, args...); err != nil {
+			return err
+		}
+		if count != len(dependsOn) {
+			return newValidationError("dependsOn", "prerequisite tasks must belong to the same holding")
+		}
+	}
+
+	// 同じ開催の依存関係をロックして、並行する更新で循環が作られないようにする
+	var edges []taskDependency
+	err := tx.SelectContext(ctx, &edges,
+		"SELECT d.* FROM `task_dependencies` d INNER JOIN `tasks` t ON d.`task_id` = t.`id` WHERE t.`holding_id` = ? FOR UPDATE",
+		task.HoldingID,
+	)
+	if err != nil {
+		return err
+	}
+	graph := make(map[int][]int)
+	for _, edge := range edges {
+		if edge.TaskID != task.ID {
+			graph[edge.TaskID] = append(graph[edge.TaskID], edge.DependsOnTaskID)
+		}
+	}
+	graph[task.ID] = dependsOn
+	if cycle := findCycle(graph, task.ID); cycle != nil {
+		path := make([]string, len(cycle))
+		for i, id := range cycle {
+			path[i] = strconv.Itoa(id)
+		}
+		return newValidationError("dependsOn", "dependency cycle: "+strings.Join(path, " -> "))
+	}
+
+	// ゴミ箱にある前提タスクとの依存関係は、復元したときのために残す
+	_, err = tx.ExecContext(ctx,
+		"DELETE d FROM `task_dependencies` d INNER JOIN `tasks` p ON d.`depends_on_task_id` = p.`id` WHERE d.`task_id` = ? AND p.`deleted_at` IS NULL",
+		task.ID,
+	)
+	if err != nil {
+		return err
+	}
+	for _, dep := range dependsOn {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO `task_dependencies` (`task_id`, `depends_on_task_id`) VALUES (?, ?)",
+			task.ID, dep,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findCycle はstartから辿ってstartに戻る経路があればその経路を返す
+func findCycle(graph map[int][]int, start int) []int {
+	visited := make(map[int]bool)
+	var path []int
+	var visit func(id int) bool
+	visit = func(id int) bool {
+		path = append(path, id)
+		for _, next := range graph[id] {
+			if next == start {
+				path = append(path, next)
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				if visit(next) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(start) {
+		return path
+	}
+	return nil
+}
+
+// copyDependencies はコピー元のタスク間の依存関係を、コピー先のタスクに複製する
+// idMapはコピー元のタスクIDからコピー先のタスクIDへの対応
+func copyDependencies(ctx context.Context, tx *sqlx.Tx, idMap map[int]int) error {
+	srcIDs := make([]int, 0, len(idMap))
+	for id := range idMap {
+		srcIDs = append(srcIDs, id)
+	}
+	deps, err := dependencyMap(ctx, tx, srcIDs)
+	if err != nil {
+		return err
+	}
+	for src, dependsOn := range deps {
+		for _, dep := range dependsOn {
+			newDep, ok := idMap[dep]
+			if !ok {
+				continue
+			}
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO `task_dependencies` (`task_id`, `depends_on_task_id`) VALUES (?, ?)",
+				idMap[src], newDep,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type blockingTaskRow struct {
+	BlockedTaskID int `db:"blocked_task_id"`
+	models.Task
+}
+
+// GetBlockingTasks は各タスクの前提タスクのうち未完了のものを取得する
+func (s *TaskService) GetBlockingTasks(ctx context.Context, taskIDs []int) (map[int][]models.Task, error) {
+	blocking := make(map[int][]models.Task)
+	if len(taskIDs) == 0 {
+		return blocking, nil
+	}
+	query, args, err := sqlx.In(
+		"SELECT d.`task_id` AS `blocked_task_id`, p.* FROM `task_dependencies` d "+
+			"INNER JOIN `tasks` p ON d.`depends_on_task_id` = p.`id` "+
+			"WHERE d.`task_id` IN (?) AND p.`completed_at` IS NULL AND p.`deleted_at` IS NULL ORDER BY p.`days_before` DESC, p.`id`",
+		taskIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var rows []blockingTaskRow
+	if err := s.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("get blocking tasks: %w", err)
+	}
+	for _, row := range rows {
+		blocking[row.BlockedTaskID] = append(blocking[row.BlockedTaskID], row.Task)
+	}
+	return blocking, nil
+}
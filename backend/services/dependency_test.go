@@ -0,0 +1,66 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph map[int][]int
+		start int
+		want  []int
+	}{
+		{
+			name:  "no dependencies",
+			graph: map[int][]int{},
+			start: 1,
+			want:  nil,
+		},
+		{
+			name:  "self loop",
+			graph: map[int][]int{1: {1}},
+			start: 1,
+			want:  []int{1, 1},
+		},
+		{
+			name:  "two node cycle",
+			graph: map[int][]int{1: {2}, 2: {1}},
+			start: 1,
+			want:  []int{1, 2, 1},
+		},
+		{
+			name:  "three node cycle",
+			graph: map[int][]int{1: {2}, 2: {3}, 3: {1}},
+			start: 1,
+			want:  []int{1, 2, 3, 1},
+		},
+		{
+			name:  "diamond without cycle",
+			graph: map[int][]int{1: {2, 3}, 2: {4}, 3: {4}},
+			start: 1,
+			want:  nil,
+		},
+		{
+			name:  "cycle not passing through start",
+			graph: map[int][]int{1: {2}, 2: {3}, 3: {2}},
+			start: 1,
+			want:  nil,
+		},
+		{
+			name:  "cycle behind a dead end",
+			graph: map[int][]int{1: {2, 3}, 2: {4}, 3: {1}},
+			start: 1,
+			want:  []int{1, 3, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findCycle(tt.graph, tt.start)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// 対象のリソースが存在しない
+	ErrNotFound = errors.New("not found")
+	// 他のリソースの状態と衝突するため操作できない
+	ErrConflict = errors.New("conflict")
+	// 操作しようとしたユーザーにその権限がない
+	ErrForbidden = errors.New("forbidden")
+	// 指定された版が現在の版と一致しない（他の更新と競合した）
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// ValidationError は入力値が不正な場合のエラー
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func newValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+// BatchValidationError はまとめて処理する項目のうち、入力値が不正だった全ての項目のエラー
+// 各エラーのFieldは項目の位置を含む（例: update.2.daysBefore）
+type BatchValidationError struct {
+	Errors []*ValidationError
+}
+
+func (e *BatchValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d items are invalid: %s", len(e.Errors), e.Errors[0].Error())
+}
+
+// Add はpathの項目のエラーを追加する。ValidationError以外のエラーは追加せずfalseを返す
+func (e *BatchValidationError) Add(path string, err error) bool {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	field := path
+	if validationErr.Field != "" {
+		field += "." + validationErr.Field
+	}
+	e.Errors = append(e.Errors, newValidationError(field, validationErr.Message))
+	return true
+}
+
+// Err はエラーが1つも無ければnilを返す
+func (e *BatchValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// notFound はsql.ErrNoRowsをErrNotFoundに置き換える
+func notFound(err error, resource string, id int) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %d: %w", resource, id, ErrNotFound)
+	}
+	return err
+}
+
+// requireAffected は更新・削除の対象行が無かった場合にErrNotFoundを返す
+// （DSNでclientFoundRowsを有効にしているため、値が変わらない更新も1行として数えられる）
+func requireAffected(result sql.Result, resource string, id int) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %d: %w", resource, id, ErrNotFound)
+	}
+	return nil
+}
+
+const (
+	mysqlErrDuplicateEntry  = 1062
+	mysqlErrNoReferencedRow = 1452
+	mysqlErrRowIsReferenced = 1451
+)
+
+func isMySQLError(err error, number uint16) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == number
+}
+
+// translateDBError は制約違反をドメインのエラーに置き換える
+// 外部キーの参照先が無い場合はfieldについてのValidationErrorにする
+func translateDBError(err error, field string) error {
+	switch {
+	case isMySQLError(err, mysqlErrNoReferencedRow):
+		return newValidationError(field, "referenced resource does not exist")
+	case isMySQLError(err, mysqlErrDuplicateEntry), isMySQLError(err, mysqlErrRowIsReferenced):
+		return fmt.Errorf("%w: %s", ErrConflict, err.Error())
+	}
+	return err
+}
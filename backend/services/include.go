@@ -0,0 +1,217 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// 関連リソースの埋め込み（include）
+// ========================================
+
+// HoldingInclude は開催と一緒に取得する関連リソースの指定
+type HoldingInclude struct {
+	Event bool
+	Tasks bool
+}
+
+// HoldingWithRelations は関連リソースを埋め込んだ開催
+// 指定されなかった関連リソースはnilになる
+type HoldingWithRelations struct {
+	models.Holding
+	Event *models.Event
+	Tasks []models.Task
+
+	sortKey string
+}
+
+// holdingQuery は開催を取得するクエリの部品
+type holdingQuery struct {
+	sortKey string
+	where   string
+	args    []any
+	orderBy string
+	limit   string
+}
+
+// includedTaskRow はLEFT JOINしたタスクの列
+// タスクのない開催ではすべてNULLになる
+type includedTaskRow struct {
+	ID            sql.NullInt64  `db:"id"`
+	HoldingID     sql.NullInt64  `db:"holding_id"`
+	Name          sql.NullString `db:"name"`
+	Anchor        sql.NullString `db:"anchor"`
+	DaysBefore    sql.NullInt64  `db:"days_before"`
+	OffsetMinutes sql.NullInt64  `db:"offset_minutes"`
+	RemindTime    sql.NullString `db:"remind_time"`
+	RemindAt      sql.NullTime   `db:"remind_at"`
+	Description   sql.NullString `db:"description"`
+	Status        sql.NullString `db:"status"`
+	CompletedAt   sql.NullTime   `db:"completed_at"`
+	Version       sql.NullInt64  `db:"version"`
+	// 前提タスクのIDのJSON配列（前提タスクが無ければNULL）
+	DependsOn sql.NullString `db:"depends_on"`
+}
+
+type holdingJoinRow struct {
+	models.Holding
+	SortKey string `db:"sort_key"`
+	// メンションのJSON配列（メンションが無ければNULL）
+	MentionsJSON sql.NullString  `db:"mentions_json"`
+	Event        models.Event    `db:"event"`
+	Task         includedTaskRow `db:"task"`
+}
+
+// 開催のメンションと、タスクの前提タスクは相関サブクエリでJSONにまとめて同じクエリで取得する
+// JSON_ARRAYAGGは順序を保証しないので、並び順はGo側で整える
+const (
+	mentionsJSONColumn = "(SELECT JSON_ARRAYAGG(JSON_OBJECT('position', m.`position`, 'type', m.`type`, 'id', m.`target_id`))" +
+		" FROM `holding_mentions` m WHERE m.`holding_id` = h.`id`) AS `mentions_json`"
+	dependsOnJSONColumn = "(SELECT JSON_ARRAYAGG(d.`depends_on_task_id`) FROM `task_dependencies` d" +
+		" INNER JOIN `tasks` p ON d.`depends_on_task_id` = p.`id` WHERE d.`task_id` = t.`id` AND p.`deleted_at` IS NULL) AS `task.depends_on`"
+)
+
+// selectHoldings は開催と指定された関連リソースを1回のクエリで取得する
+// 絞り込みと件数の制限はサブクエリで開催に対して行い、その結果にイベントとタスクをJOINする
+func (s *TaskService) selectHoldings(ctx context.Context, hq holdingQuery, include HoldingInclude) ([]HoldingWithRelations, error) {
+	columns := "h.*, " + mentionsJSONColumn
+	joins := ""
+	taskOrder := ""
+	if include.Event {
+		columns += ", e.`id` AS `event.id`, e.`name` AS `event.name`, e.`catch_up_policy` AS `event.catch_up_policy`," +
+			" e.`blocked_policy` AS `event.blocked_policy`, e.`archived_at` AS `event.archived_at`, e.`version` AS `event.version`"
+		joins += " INNER JOIN `events` e ON e.`id` = h.`event_id`"
+	}
+	if include.Tasks {
+		columns += ", t.`id` AS `task.id`, t.`holding_id` AS `task.holding_id`, t.`name` AS `task.name`, t.`anchor` AS `task.anchor`," +
+			" t.`days_before` AS `task.days_before`, t.`description` AS `task.description`, t.`status` AS `task.status`," +
+			" t.`completed_at` AS `task.completed_at`, t.`offset_minutes` AS `task.offset_minutes`," +
+			" t.`remind_time` AS `task.remind_time`, t.`remind_at` AS `task.remind_at`, t.`version` AS `task.version`, " +
+			dependsOnJSONColumn
+		joins += " LEFT JOIN `tasks` t ON t.`holding_id` = h.`id` AND t.`deleted_at` IS NULL"
+		// 開催のタスク一覧と同じ順序
+		taskOrder = ", t.`days_before` DESC, t.`id` ASC"
+	}
+
+	sortKey := hq.sortKey
+	if sortKey == "" {
+		sortKey = "''"
+	}
+	query := fmt.Sprintf(
+		"SELECT %s FROM (SELECT h.*, %s AS sort_key FROM `holdings` h %s %s %s) h%s %s%s",
+		columns, sortKey, hq.where, hq.orderBy, hq.limit, joins, hq.orderBy, taskOrder,
+	)
+
+	var rows []holdingJoinRow
+	if err := s.db.SelectContext(ctx, &rows, query, hq.args...); err != nil {
+		return nil, err
+	}
+
+	// 同じ開催の行は連続して返るのでまとめる
+	holdings := []HoldingWithRelations{}
+	for _, row := range rows {
+		if len(holdings) == 0 || holdings[len(holdings)-1].ID != row.ID {
+			holding := HoldingWithRelations{Holding: row.Holding, sortKey: row.SortKey}
+			mentions, err := decodeMentionsJSON(row.ID, row.MentionsJSON)
+			if err != nil {
+				return nil, err
+			}
+			holding.Mentions = mentions
+			if include.Event {
+				event := row.Event
+				holding.Event = &event
+			}
+			if include.Tasks {
+				holding.Tasks = []models.Task{}
+			}
+			holdings = append(holdings, holding)
+		}
+		if include.Tasks && row.Task.ID.Valid {
+			task, err := row.Task.toTask()
+			if err != nil {
+				return nil, err
+			}
+			last := &holdings[len(holdings)-1]
+			last.Tasks = append(last.Tasks, task)
+		}
+	}
+	return holdings, nil
+}
+
+// decodeMentionsJSON はmentions_jsonの列を並び順どおりのメンションにする
+func decodeMentionsJSON(holdingID int, col sql.NullString) ([]models.HoldingMention, error) {
+	mentions := []models.HoldingMention{}
+	if !col.Valid {
+		return mentions, nil
+	}
+	var rows []struct {
+		Position int                `json:"position"`
+		Type     models.MentionType `json:"type"`
+		ID       string             `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(col.String), &rows); err != nil {
+		return nil, fmt.Errorf("decode mentions of holding %d: %w", holdingID, err)
+	}
+	for _, row := range rows {
+		mentions = append(mentions, models.HoldingMention{HoldingID: holdingID, Position: row.Position, Type: row.Type, TargetID: row.ID})
+	}
+	slices.SortFunc(mentions, func(a, b models.HoldingMention) int {
+		return cmp.Compare(a.Position, b.Position)
+	})
+	return mentions, nil
+}
+
+func (r includedTaskRow) toTask() (models.Task, error) {
+	task := models.Task{
+		ID:          int(r.ID.Int64),
+		HoldingID:   int(r.HoldingID.Int64),
+		Name:        r.Name.String,
+		Anchor:      models.TaskAnchor(r.Anchor.String),
+		DaysBefore:  int(r.DaysBefore.Int64),
+		Description: r.Description.String,
+		Status:      models.TaskStatus(r.Status.String),
+		Version:     int(r.Version.Int64),
+	}
+	if r.CompletedAt.Valid {
+		task.CompletedAt = &r.CompletedAt.Time
+	}
+	if r.OffsetMinutes.Valid {
+		offset := int(r.OffsetMinutes.Int64)
+		task.OffsetMinutes = &offset
+	}
+	if r.RemindTime.Valid {
+		task.RemindTime = &r.RemindTime.String
+	}
+	if r.RemindAt.Valid {
+		task.RemindAt = &r.RemindAt.Time
+	}
+	if r.DependsOn.Valid {
+		if err := json.Unmarshal([]byte(r.DependsOn.String), &task.DependsOn); err != nil {
+			return task, fmt.Errorf("decode dependencies of task %d: %w", task.ID, err)
+		}
+		slices.Sort(task.DependsOn)
+	}
+	return task, nil
+}
+
+// GetHoldingWithRelations は関連リソースを埋め込んだ開催を取得する
+func (s *TaskService) GetHoldingWithRelations(ctx context.Context, id int, include HoldingInclude) (HoldingWithRelations, error) {
+	holdings, err := s.selectHoldings(ctx, holdingQuery{
+		where:   "WHERE h.`id` = ? AND h.`deleted_at` IS NULL",
+		args:    []any{id},
+		orderBy: "ORDER BY h.`id`",
+	}, include)
+	if err != nil {
+		return HoldingWithRelations{}, err
+	}
+	if len(holdings) == 0 {
+		return HoldingWithRelations{}, notFound(sql.ErrNoRows, "holding", id)
+	}
+	return holdings[0], nil
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// 一覧取得（フィルタ・並び替え・カーソルページネーション）
+// ========================================
+
+// 期間の絞り込み
+const (
+	WhenUpcoming = "upcoming"
+	WhenPast     = "past"
+)
+
+// ListOptions は一覧取得に共通する並び替えとページネーションの指定
+type ListOptions struct {
+	// 並び替えのキー。先頭に"-"を付けると降順
+	Sort string
+	// 前のページのレスポンスで返されたカーソル
+	Cursor string
+	// 1ページの件数。0の場合は全件を返す
+	Limit int
+}
+
+// Page は一覧取得の結果。NextCursorが空なら最後のページ
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+type HoldingFilter struct {
+	EventID   *int
+	ChannelID string
+	// 開催日の範囲（両端を含む）
+	From *time.Time
+	To   *time.Time
+	// WhenUpcoming: 今日以降の開催（開催中を含む） / WhenPast: 昨日までに終わった開催
+	When string
+	// trueならアーカイブしたイベントの開催も含める
+	IncludeArchived bool
+	// 通知先チャンネルの状態（定期的な確認の結果）
+	ChannelStatus models.ChannelStatus
+	// 一緒に取得する関連リソース
+	Include HoldingInclude
+	ListOptions
+}
+
+type TaskFilter struct {
+	HoldingID *int
+	EventID   *int
+	ChannelID string
+	Status    models.TaskStatus
+	// リマインド日の範囲（両端を含む）
+	From *time.Time
+	To   *time.Time
+	// WhenUpcoming: リマインド日が今日以降 / WhenPast: リマインド日が昨日まで
+	When string
+	// trueならアーカイブしたイベントのタスクも含める
+	IncludeArchived bool
+	ListOptions
+}
+
+const maxListLimit = 500
+
+// notArchivedCond は開催のイベントがアーカイブされていないことを表すSQLの条件
+const notArchivedCond = "h.`event_id` IN (SELECT `id` FROM `events` WHERE `archived_at` IS NULL)"
+
+// holdingLastDateExpr は開催の最終日を求めるSQL式
+const holdingLastDateExpr = "COALESCE(h.`end_date`, h.`date`)"
+
+var holdingSortColumns = map[string]string{
+	"date": "h.`date`",
+	"name": "h.`name`",
+	"id":   "h.`id`",
+}
+
+// remindDateExpr はタスクのリマインド日を求めるSQL式
+const remindDateExpr = "DATE(t.`remind_at`)"
+
+// remindAtSortExpr はリマインド日時で並び替えるためのSQL式
+// remind_atが未計算（NULL）のタスクは最後に並べ、並び替えキーとカーソルの比較がNULLにならないようにする
+const remindAtSortExpr = "COALESCE(t.`remind_at`, TIMESTAMP('9999-12-31 23:59:59'))"
+
+var taskSortColumns = map[string]string{
+	"remind_date": remindAtSortExpr,
+	"days_before": "t.`days_before`",
+	"name":        "t.`name`",
+	"id":          "t.`id`",
+}
+
+// cursor は最後に返した行の並び替えキーとIDを保持する
+type cursor struct {
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, newValidationError("cursor", "invalid cursor")
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, newValidationError("cursor", "invalid cursor")
+	}
+	return c, nil
+}
+
+// listQuery はWHERE句を組み立てるためのヘルパー
+type listQuery struct {
+	conds []string
+	args  []any
+}
+
+func (q *listQuery) where(cond string, args ...any) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.conds, " AND ")
+}
+
+// paginate は並び替え・カーソル・件数の指定をクエリに反映し、ORDER BY句とLIMIT句を返す
+// idColumnは並び替えキーが同じ行の順序を決めるための列
+func (q *listQuery) paginate(opts ListOptions, columns map[string]string, defaultSort string, idColumn string) (orderBy string, limit string, err error) {
+	sort := opts.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	desc := strings.HasPrefix(sort, "-")
+	column, ok := columns[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return "", "", newValidationError("sort", fmt.Sprintf("unknown sort key %q", sort))
+	}
+	if opts.Limit < 0 {
+		return "", "", newValidationError("limit", "must not be negative")
+	}
+	if opts.Limit > maxListLimit {
+		return "", "", newValidationError("limit", fmt.Sprintf("must be at most %d", maxListLimit))
+	}
+
+	op, dir := ">", "ASC"
+	if desc {
+		op, dir = "<", "DESC"
+	}
+
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return "", "", err
+		}
+		q.where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", column, op, idColumn),
+			c.Value, c.Value, c.ID,
+		)
+	}
+
+	orderBy = fmt.Sprintf("ORDER BY %s %s, %s %s", column, dir, idColumn, dir)
+	if opts.Limit > 0 {
+		// 次のページがあるかを判定するために1件多く取得する
+		limit = fmt.Sprintf("LIMIT %d", opts.Limit+1)
+	}
+	return orderBy, limit, nil
+}
+
+func sortKeyExpr(opts ListOptions, columns map[string]string, defaultSort string) string {
+	sort := opts.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	return fmt.Sprintf("CAST(%s AS CHAR)", columns[strings.TrimPrefix(sort, "-")])
+}
+
+// nextPage はLIMIT+1件の取得結果からページとカーソルを作る
+func nextPage[T any](rows []T, limit int, key func(T) cursor) Page[T] {
+	if limit == 0 || len(rows) <= limit {
+		if rows == nil {
+			rows = []T{}
+		}
+		return Page[T]{Items: rows}
+	}
+	rows = rows[:limit]
+	return Page[T]{Items: rows, NextCursor: encodeCursor(key(rows[limit-1]))}
+}
+
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func applyWhen(q *listQuery, when string, column string) error {
+	switch when {
+	case "":
+	case WhenUpcoming:
+		q.where(column+" >= ?", today())
+	case WhenPast:
+		q.where(column+" < ?", today())
+	default:
+		return newValidationError("when", "must be upcoming or past")
+	}
+	return nil
+}
+
+func (s *TaskService) ListHoldings(ctx context.Context, filter HoldingFilter) (Page[HoldingWithRelations], error) {
+	var q listQuery
+	q.where("h.`deleted_at` IS NULL")
+	if !filter.IncludeArchived {
+		q.where(notArchivedCond)
+	}
+	if filter.EventID != nil {
+		q.where("h.`event_id` = ?", *filter.EventID)
+	}
+	if filter.ChannelID != "" {
+		q.where("h.`channel_id` = ?", filter.ChannelID)
+	}
+	if filter.ChannelStatus != "" {
+		q.where("h.`channel_status` = ?", filter.ChannelStatus)
+	}
+	// 複数日にわたる開催は期間が重なっていれば対象にする
+	if filter.From != nil {
+		q.where(holdingLastDateExpr+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q.where("h.`date` <= ?", *filter.To)
+	}
+	if err := applyWhen(&q, filter.When, holdingLastDateExpr); err != nil {
+		return Page[HoldingWithRelations]{}, err
+	}
+	orderBy, limit, err := q.paginate(filter.ListOptions, holdingSortColumns, "-date", "h.`id`")
+	if err != nil {
+		return Page[HoldingWithRelations]{}, err
+	}
+
+	holdings, err := s.selectHoldings(ctx, holdingQuery{
+		sortKey: sortKeyExpr(filter.ListOptions, holdingSortColumns, "-date"),
+		where:   q.whereClause(),
+		args:    q.args,
+		orderBy: orderBy,
+		limit:   limit,
+	}, filter.Include)
+	if err != nil {
+		return Page[HoldingWithRelations]{}, err
+	}
+
+	return nextPage(holdings, filter.Limit, func(h HoldingWithRelations) cursor {
+		return cursor{Value: h.sortKey, ID: h.ID}
+	}), nil
+}
+
+type taskRow struct {
+	models.Task
+	SortKey string `db:"sort_key"`
+}
+
+// ListTasks は開催をまたいでタスクを取得する
+func (s *TaskService) ListTasks(ctx context.Context, filter TaskFilter) (Page[models.Task], error) {
+	var q listQuery
+	q.where("t.`deleted_at` IS NULL")
+	if !filter.IncludeArchived {
+		q.where(notArchivedCond)
+	}
+	if filter.HoldingID != nil {
+		q.where("t.`holding_id` = ?", *filter.HoldingID)
+	}
+	if filter.EventID != nil {
+		q.where("h.`event_id` = ?", *filter.EventID)
+	}
+	if filter.ChannelID != "" {
+		q.where("h.`channel_id` = ?", filter.ChannelID)
+	}
+	if filter.Status != "" {
+		q.where("t.`status` = ?", filter.Status)
+	}
+	if filter.From != nil {
+		q.where(remindDateExpr+" >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q.where(remindDateExpr+" <= ?", *filter.To)
+	}
+	if err := applyWhen(&q, filter.When, remindDateExpr); err != nil {
+		return Page[models.Task]{}, err
+	}
+	orderBy, limit, err := q.paginate(filter.ListOptions, taskSortColumns, "remind_date", "t.`id`")
+	if err != nil {
+		return Page[models.Task]{}, err
+	}
+
+	query := fmt.Sprintf(
+		"SELECT t.*, %s AS sort_key FROM `tasks` t INNER JOIN `holdings` h ON t.`holding_id` = h.`id` %s %s %s",
+		sortKeyExpr(filter.ListOptions, taskSortColumns, "remind_date"),
+		q.whereClause(),
+		orderBy,
+		limit,
+	)
+	var rows []taskRow
+	if err := s.db.SelectContext(ctx, &rows, query, q.args...); err != nil {
+		return Page[models.Task]{}, err
+	}
+
+	page := nextPage(rows, filter.Limit, func(r taskRow) cursor {
+		return cursor{Value: r.SortKey, ID: r.ID}
+	})
+	tasks := make([]models.Task, len(page.Items))
+	for i, r := range page.Items {
+		tasks[i] = r.Task
+	}
+	if err := s.attachDependencies(ctx, tasks); err != nil {
+		return Page[models.Task]{}, err
+	}
+	return Page[models.Task]{Items: tasks, NextCursor: page.NextCursor}, nil
+}
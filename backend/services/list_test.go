@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListTasksSortByRemindDateWithNullRemindAt(t *testing.T) {
+	// remind_atがNULLのタスクはCAST(COALESCE(...) AS CHAR)でこの値になる
+	const nullRemindAtKey = "9999-12-31 23:59:59"
+
+	tests := []struct {
+		name     string
+		sort     string
+		wantCond string
+		wantDir  string
+	}{
+		{name: "ascending", sort: "remind_date", wantCond: remindAtSortExpr + " > ?", wantDir: "ASC"},
+		{name: "descending", sort: "-remind_date", wantCond: remindAtSortExpr + " < ?", wantDir: "DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ListOptions{Sort: tt.sort, Limit: 1}
+
+			// 1ページ目の最後の行がremind_atの無いタスクでもカーソルを作れる
+			rows := []taskRow{{SortKey: nullRemindAtKey}, {SortKey: nullRemindAtKey}}
+			rows[0].ID, rows[1].ID = 1, 2
+			page := nextPage(rows, opts.Limit, func(r taskRow) cursor {
+				return cursor{Value: r.SortKey, ID: r.ID}
+			})
+			if page.NextCursor == "" {
+				t.Fatal("NextCursor is empty")
+			}
+			opts.Cursor = page.NextCursor
+
+			var q listQuery
+			orderBy, _, err := q.paginate(opts, taskSortColumns, "remind_date", "t.`id`")
+			if err != nil {
+				t.Fatalf("paginate() error = %v", err)
+			}
+			if len(q.conds) != 1 || !strings.Contains(q.conds[0], tt.wantCond) {
+				t.Errorf("cursor condition = %v, want it to contain %q", q.conds, tt.wantCond)
+			}
+			if len(q.args) != 3 || q.args[0] != nullRemindAtKey || q.args[2] != 1 {
+				t.Errorf("cursor args = %v, want [%s %s 1]", q.args, nullRemindAtKey, nullRemindAtKey)
+			}
+			if want := "ORDER BY " + remindAtSortExpr + " " + tt.wantDir; !strings.HasPrefix(orderBy, want) {
+				t.Errorf("orderBy = %q, want prefix %q", orderBy, want)
+			}
+			if got, want := sortKeyExpr(opts, taskSortColumns, "remind_date"), "CAST("+remindAtSortExpr+" AS CHAR)"; got != want {
+				t.Errorf("sortKeyExpr() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPaginateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr bool
+	}{
+		{name: "all", limit: 0},
+		{name: "max", limit: maxListLimit},
+		{name: "too large", limit: maxListLimit + 1, wantErr: true},
+		{name: "negative", limit: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q listQuery
+			_, _, err := q.paginate(ListOptions{Limit: tt.limit}, taskSortColumns, "remind_date", "t.`id`")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("paginate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
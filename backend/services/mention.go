@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// 開催のメンション（holding_mentions）
+// ========================================
+
+// 1つの開催でメンションできるユーザー・グループの数の上限
+const maxHoldingMentions = 50
+
+func validateMentions(mentions []models.HoldingMention) error {
+	if len(mentions) > maxHoldingMentions {
+		return newValidationError("mentions", fmt.Sprintf("must have at most %d items", maxHoldingMentions))
+	}
+	seen := make(map[models.HoldingMention]bool, len(mentions))
+	for i, mention := range mentions {
+		if !mention.Type.Valid() {
+			return newValidationError(fmt.Sprintf("mentions.%d.type", i), "must be one of user, group")
+		}
+		if mention.TargetID == "" {
+			return newValidationError(fmt.Sprintf("mentions.%d.id", i), "id is required")
+		}
+		key := models.HoldingMention{Type: mention.Type, TargetID: mention.TargetID}
+		if seen[key] {
+			return newValidationError(fmt.Sprintf("mentions.%d.id", i), "duplicated mention")
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// mentionMap は各開催のメンションを並び順に取得する
+func mentionMap(ctx context.Context, q sqlx.QueryerContext, holdingIDs []int) (map[int][]models.HoldingMention, error) {
+	mentions := make(map[int][]models.HoldingMention, len(holdingIDs))
+	if len(holdingIDs) == 0 {
+		return mentions, nil
+	}
+	query, args, err := sqlx.In(
+		"SELECT * FROM `holding_mentions` WHERE `holding_id` IN (?) ORDER BY `holding_id`, `position`",
+		holdingIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var rows []models.HoldingMention
+	if err := sqlx.SelectContext(ctx, q, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		mentions[row.HoldingID] = append(mentions[row.HoldingID], row)
+	}
+	return mentions, nil
+}
+
+// attachMentions は開催のMentionsを埋める
+func (s *TaskService) attachMentions(ctx context.Context, holdings []models.Holding) error {
+	ids := make([]int, len(holdings))
+	for i, holding := range holdings {
+		ids[i] = holding.ID
+	}
+	mentions, err := mentionMap(ctx, s.db, ids)
+	if err != nil {
+		s.logger.Error("failed to get holding mentions", slog.String("err", err.Error()))
+		return err
+	}
+	for i := range holdings {
+		holdings[i].Mentions = mentions[holdings[i].ID]
+		if holdings[i].Mentions == nil {
+			holdings[i].Mentions = []models.HoldingMention{}
+		}
+	}
+	return nil
+}
+
+// setMentions は開催のメンションをmentionsで置き換える
+func (s *TaskService) setMentions(ctx context.Context, tx *sqlx.Tx, holdingID int, mentions []models.HoldingMention) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `holding_mentions` WHERE `holding_id` = ?", holdingID); err != nil {
+		s.logger.Error("failed to delete holding mentions", slog.String("err", err.Error()))
+		return err
+	}
+	for i, mention := range mentions {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO `holding_mentions` (`holding_id`, `position`, `type`, `target_id`) VALUES (?, ?, ?, ?)",
+			holdingID, i, mention.Type, mention.TargetID,
+		)
+		if err != nil {
+			s.logger.Error("failed to insert holding mention", slog.String("err", err.Error()))
+			return err
+		}
+	}
+	return nil
+}
+
+// BackfillHoldingMentions は旧形式の自由記述のメンションしか持たない開催を、ユーザー・グループのメンションに移行する
+// 全ての名前が分かった開催だけを移し、旧形式のメンションを消す。分からない名前を含む開催はそのまま残す
+// 何度実行しても結果は変わらない
+func (s *TaskService) BackfillHoldingMentions(ctx context.Context, traqSvc *TraQService) error {
+	var holdings []models.Holding
+	err := s.db.SelectContext(ctx, &holdings,
+		"SELECT * FROM `holdings` h WHERE h.`mention` <> '' AND NOT EXISTS (SELECT 1 FROM `holding_mentions` m WHERE m.`holding_id` = h.`id`)",
+	)
+	if err != nil {
+		s.logger.Error("failed to get holdings with legacy mention", slog.String("err", err.Error()))
+		return err
+	}
+
+	for _, holding := range holdings {
+		mentions, ok, err := traqSvc.ResolveLegacyMention(ctx, holding.Mention)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.logger.Warn("legacy mention could not be resolved, keeping it as is",
+				slog.Int("holdingId", holding.ID), slog.String("mention", holding.Mention))
+			continue
+		}
+		if err := s.migrateLegacyMention(ctx, holding, mentions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskService) migrateLegacyMention(ctx context.Context, holding models.Holding, mentions []models.HoldingMention) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 読み込んでから書き込むまでに編集された開催は移行しない
+	result, err := tx.ExecContext(ctx,
+		"UPDATE `holdings` SET `mention` = '', `version` = `version` + 1 WHERE `id` = ? AND `version` = ?",
+		holding.ID, holding.Version,
+	)
+	if err != nil {
+		s.logger.Error("failed to clear legacy mention", slog.String("err", err.Error()))
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return err
+	}
+	if err := s.setMentions(ctx, tx, holding.ID, mentions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
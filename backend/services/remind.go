@@ -2,64 +2,547 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/pirosiki197/event_reminder/metrics"
 	"github.com/pirosiki197/event_reminder/models"
 	"github.com/robfig/cron/v3"
 	"github.com/traPtitech/go-traq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// DeliveryMode は送信中に中断されたリマインドの扱いを表す
+type DeliveryMode string
+
+const (
+	// 中断されたリマインドを再送する（重複する可能性がある）
+	DeliveryAtLeastOnce DeliveryMode = "at-least-once"
+	// 中断されたリマインドは再送しない（届かない可能性がある）
+	DeliveryAtMostOnce DeliveryMode = "at-most-once"
+)
+
+func ParseDeliveryMode(s string) (DeliveryMode, error) {
+	switch DeliveryMode(s) {
+	case "", DeliveryAtLeastOnce:
+		return DeliveryAtLeastOnce, nil
+	case DeliveryAtMostOnce:
+		return DeliveryAtMostOnce, nil
+	}
+	return "", fmt.Errorf("unknown delivery mode: %q", s)
+}
+
+// ParseRemindInterval はリマインド対象を確認する間隔を解析する（未指定の場合は1分）
+func ParseRemindInterval(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultRemindInterval, nil
+	}
+	interval, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid remind interval: %w", err)
+	}
+	if interval < time.Second {
+		return 0, fmt.Errorf("remind interval must be at least 1s: %s", s)
+	}
+	return interval, nil
+}
+
 type RemindService struct {
-	taskSvc *TaskService
-	traqSvc *TraQService
-	client  *traq.APIClient
-	logger  *slog.Logger
+	taskSvc      *TaskService
+	traqSvc      *TraQService
+	client       *traq.APIClient
+	logger       *slog.Logger
+	deliveryMode DeliveryMode
+	// リマインド日時を迎えたタスクを確認する間隔
+	interval time.Duration
+	// ゴミ箱に入れたものを完全に削除するまでの期間（0の場合は削除しない）
+	trashRetention time.Duration
+
+	// 複数レプリカで動かした際にタスクの確保者を区別するためのID
+	instanceID string
+	mu         sync.Mutex
+	// 起動前に停止していた間の取りこぼし（次の実行で適用し、成功したら空にする）
+	missed missedWindow
+
+	cron     *cron.Cron
+	schedule cron.Schedule
+	// 起動時の復旧処理など、cron以外から実行中の処理
+	wg sync.WaitGroup
+	// Stopの期限を過ぎた際に実行中のリマインド送信を打ち切るためのcontext
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-func NewRemindService(taskSvc *TaskService, traqSvc *TraQService, logger *slog.Logger, client *traq.APIClient) *RemindService {
+func NewRemindService(taskSvc *TaskService, traqSvc *TraQService, logger *slog.Logger, client *traq.APIClient, deliveryMode DeliveryMode, interval time.Duration, trashRetention time.Duration) *RemindService {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &RemindService{
-		taskSvc: taskSvc,
-		traqSvc: traqSvc,
-		client:  client,
-		logger:  logger,
+		taskSvc:        taskSvc,
+		traqSvc:        traqSvc,
+		client:         client,
+		logger:         logger,
+		deliveryMode:   deliveryMode,
+		interval:       interval,
+		trashRetention: trashRetention,
+		instanceID:     newRandomID(),
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 }
 
+// tasks.claimed_byに収まるよう、ホスト名は長い場合に切り詰める（区別は末尾の乱数で行う）
+const maxRandomIDHostname = 64 - len("-") - 8
+
+func newRandomID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	if len(hostname) > maxRandomIDHostname {
+		hostname = hostname[:maxRandomIDHostname]
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return fmt.Sprintf("%s-%s", hostname, hex.EncodeToString(b))
+}
+
+const (
+	remindJobName         = "remind"
+	defaultRemindInterval = time.Minute
+	// sendingのまま残っているタスクを中断されたものとみなすまでの時間
+	remindSendingTimeout = 10 * time.Minute
+	// 送信失敗時に再試行する最大回数
+	remindMaxAttempts = 3
+	// ゴミ箱の保存期間を過ぎたものを確認する間隔
+	trashPurgeInterval = time.Hour
+)
+
+// Start はリマインド日時を迎えたタスクの確認を一定間隔で始める
 func (rs *RemindService) Start() {
-	c := cron.New()
-	c.AddFunc("0 8 * * *", func() {
-		rs.logger.Info("cron job started")
-		tasks, err := rs.taskSvc.GetTasksToRemind()
+	schedule := cron.Every(rs.interval)
+	rs.schedule = schedule
+	// cronの初回実行より先に決めておき、どちらが先に実行しても取りこぼしとして扱われるようにする
+	rs.missed = rs.detectMissedRuns(schedule)
+
+	rs.cron = cron.New()
+	rs.cron.Schedule(schedule, cron.FuncJob(rs.runRemind))
+	rs.cron.Schedule(cron.Every(remindSendingTimeout), cron.FuncJob(rs.reconcile))
+	rs.cron.Schedule(cron.Every(channelCheckInterval), cron.FuncJob(rs.checkChannels))
+	if rs.trashRetention > 0 {
+		rs.cron.Schedule(cron.Every(trashPurgeInterval), cron.FuncJob(rs.purgeTrash))
+	}
+	rs.cron.Start()
+
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+		rs.reconcile()
+		// 停止中に予定時刻を迎えていた場合は、次の定期実行を待たずに取りこぼした分を送る
+		if !rs.missed.empty() {
+			rs.runRemind()
+		}
+	}()
+}
+
+// missedWindow は停止していたために予定どおりに実行できなかった期間を表す
+// この期間にリマインド日時を迎えたタスクには、イベントの取りこぼしの扱い（CatchUpPolicy）を適用する
+type missedWindow struct {
+	// 最後に成功した実行の時刻
+	from time.Time
+	// 実行できなかった予定時刻のうち最後のもの
+	to time.Time
+}
+
+func (w missedWindow) empty() bool {
+	return !w.to.After(w.from)
+}
+
+// contains はタスクのリマインド日時がこの期間にあったかを返す
+func (w missedWindow) contains(task models.Task) bool {
+	if w.empty() || task.RemindAt == nil {
+		return false
+	}
+	return task.RemindAt.After(w.from) && !task.RemindAt.After(w.to)
+}
+
+// missedRuns はlastRunAtの後からnowまでに実行できなかった予定時刻の期間を返す
+func missedRuns(schedule cron.Schedule, lastRunAt, now time.Time) missedWindow {
+	window := missedWindow{from: lastRunAt, to: lastRunAt}
+	for next := schedule.Next(lastRunAt); !next.After(now); next = schedule.Next(next) {
+		window.to = next
+	}
+	return window
+}
+
+// detectMissedRuns は最後に成功した実行の時刻から、停止中に実行できなかった期間を求める
+func (rs *RemindService) detectMissedRuns(schedule cron.Schedule) missedWindow {
+	lastRunAt, err := rs.taskSvc.GetLastRunAt(rs.ctx, remindJobName)
+	if errors.Is(err, sql.ErrNoRows) {
+		rs.logger.Info("no previous remind run recorded, skipping catch-up")
+		return missedWindow{}
+	}
+	if err != nil {
+		// 分からない場合は取りこぼしとして扱わず、通常どおり送る
+		rs.logger.Error("failed to get last run", slog.String("err", err.Error()))
+		return missedWindow{}
+	}
+
+	window := missedRuns(schedule, lastRunAt, time.Now())
+	if !window.empty() {
+		rs.logger.Info("missed scheduled remind runs detected",
+			slog.Time("lastRunAt", window.from), slog.Time("lastMissedRun", window.to))
+	}
+	return window
+}
+
+// catchUpAction はタスクの送り方を返す
+// 作成時点で既に期限切れだったタスクと停止中にリマインド日時を迎えたタスクにはイベントの設定を適用し、
+// それ以外は通常どおり個別に送る（all）。イベントは必要な場合のみ取得する
+func catchUpAction(task models.Task, holding models.Holding, missed missedWindow, event func() (models.Event, error)) (models.CatchUpPolicy, error) {
+	if !task.OverdueOnCreation(holding) && !missed.contains(task) {
+		return models.CatchUpPolicyAll, nil
+	}
+	e, err := event()
+	if err != nil {
+		return "", err
+	}
+	return e.CatchUpPolicy, nil
+}
+
+// Stop は新しいリマインドの実行を止め、実行中のものが終わるのを待つ
+// ctxの期限までに終わらなかった場合は送信を打ち切る（打ち切られたタスクは次回起動時に復旧される）
+func (rs *RemindService) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		if rs.cron != nil {
+			<-rs.cron.Stop().Done()
+		}
+		rs.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		rs.cancel()
+		return nil
+	case <-ctx.Done():
+		rs.cancel()
+		return ctx.Err()
+	}
+}
+
+// 定期実行が予定時刻からこれ以上遅れていたらスケジューラが止まっているとみなす
+const remindStaleThreshold = time.Hour
+
+// CheckHealth は最後にリマインドを実行した時刻を返し、予定どおり実行されていなければエラーを返す
+// 一度も実行されていない場合はゼロ値の時刻を返す
+func (rs *RemindService) CheckHealth(ctx context.Context) (time.Time, error) {
+	lastRunAt, err := rs.taskSvc.GetLastRunAt(ctx, remindJobName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if rs.schedule != nil {
+		if next := rs.schedule.Next(lastRunAt); time.Since(next) > remindStaleThreshold {
+			return lastRunAt, fmt.Errorf("scheduled run at %s has not completed", next.Format(time.RFC3339))
+		}
+	}
+	return lastRunAt, nil
+}
+
+// sendingのまま取り残されたタスクを配送モードに応じて復旧する
+func (rs *RemindService) reconcile() {
+	to := models.TaskStatusPending
+	if rs.deliveryMode == DeliveryAtMostOnce {
+		to = models.TaskStatusFailed
+	}
+
+	n, err := rs.taskSvc.ReconcileStuckTasks(rs.ctx, time.Now().Add(-remindSendingTimeout), to)
+	if err != nil {
+		rs.logger.Error("failed to reconcile stuck tasks", slog.String("err", err.Error()))
+		return
+	}
+	if n == 0 {
+		return
+	}
+	rs.logger.Warn("reconciled tasks stuck in sending", slog.Int64("count", n), slog.String("to", string(to)))
+
+	// 再送対象に戻したタスクは次の定期実行を待たずに送る
+	if to == models.TaskStatusPending {
+		rs.runRemind()
+	}
+}
+
+// 保存期間を過ぎたゴミ箱の中身を完全に削除する
+func (rs *RemindService) purgeTrash() {
+	n, err := rs.taskSvc.PurgeTrash(rs.ctx, time.Now().Add(-rs.trashRetention))
+	if err != nil {
+		rs.logger.Error("failed to purge trash", slog.String("err", err.Error()))
+		return
+	}
+	if n > 0 {
+		rs.logger.Info("purged trash", slog.Int64("count", n))
+	}
+}
+
+func (rs *RemindService) runRemind() {
+	// cronと起動時の取りこぼし・復旧後の再送が重ならないようにする
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	// 停止済みの場合は新たに送信を始めない
+	if rs.ctx.Err() != nil {
+		return
+	}
+
+	rs.logger.Debug("cron job started")
+	startedAt := time.Now()
+	err := rs.remind(rs.ctx, rs.missed)
+	metrics.RemindPassDuration.Observe(time.Since(startedAt).Seconds())
+	if err != nil {
+		metrics.RemindPassesTotal.WithLabelValues("error").Inc()
+		rs.logger.Error("remind job failed", slog.String("err", err.Error()))
+		return
+	}
+	metrics.RemindPassesTotal.WithLabelValues("success").Inc()
+	rs.missed = missedWindow{}
+	if err := rs.taskSvc.UpdateLastRunAt(context.WithoutCancel(rs.ctx), remindJobName, startedAt); err != nil {
+		rs.logger.Error("failed to record last run", slog.String("err", err.Error()))
+	}
+	rs.logger.Debug("cron job finished")
+}
+
+func (rs *RemindService) remind(ctx context.Context, missed missedWindow) error {
+	ctx, span := tracer.Start(ctx, "RemindService.remind")
+	defer span.End()
+
+	tasks, err := rs.taskSvc.ClaimTasksToRemind(ctx, rs.instanceID, newRandomID())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("claim pending reminds: %w", err)
+	}
+	metrics.RemindTasksSelected.Add(float64(len(tasks)))
+	span.SetAttributes(attribute.Int("remind.tasks_selected", len(tasks)))
+
+	// 送信後の状態更新は、停止処理で送信が打ち切られた場合でも反映させる
+	stateCtx := context.WithoutCancel(ctx)
+
+	holdings := make(map[int]models.Holding)
+	events := make(map[int]models.Event)
+	mentions := make(map[int]string)
+	mentionFor := func(holding models.Holding) (string, error) {
+		if mention, ok := mentions[holding.ID]; ok {
+			return mention, nil
+		}
+		mention, err := rs.mentionText(ctx, holding)
 		if err != nil {
-			rs.logger.Error("failed to get pending reminds", slog.String("err", err.Error()))
-			return
+			return "", err
 		}
+		mentions[holding.ID] = mention
+		return mention, nil
+	}
+	// 作成時点で期限切れだったタスクと停止中に取りこぼしたタスクを開催ごとにまとめる
+	overdueTasks := make(map[int][]models.Task)
 
-		for _, task := range tasks {
-			event, err := rs.taskSvc.GetHoldingByID(task.HoldingID)
+	// 未完了の前提タスク（前提タスクの完了を待つイベントのタスクは確保時点で除外されている）
+	taskIDs := make([]int, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+	blocking, err := rs.taskSvc.GetBlockingTasks(ctx, taskIDs)
+	if err != nil {
+		// 前提タスクが分からなくてもリマインド自体は送る
+		rs.logger.Error("failed to get blocking tasks", slog.String("err", err.Error()))
+		blocking = map[int][]models.Task{}
+	}
+	checklists, err := rs.taskSvc.GetChecklistItemsByTaskIDs(ctx, taskIDs)
+	if err != nil {
+		// チェックリストが分からなくてもリマインド自体は送る
+		rs.logger.Error("failed to get checklist items", slog.String("err", err.Error()))
+		checklists = map[int][]models.ChecklistItem{}
+	}
+
+	for _, task := range tasks {
+		holding, ok := holdings[task.HoldingID]
+		if !ok {
+			holding, err = rs.taskSvc.GetHoldingByID(ctx, task.HoldingID)
 			if err != nil {
 				rs.logger.Error("failed to get holding info", slog.String("err", err.Error()))
+				rs.markFailed(stateCtx, task, "holding_lookup", err)
 				continue
 			}
-			err = rs.sendRemind(context.Background(), task, event)
-			if err != nil {
-				rs.logger.Error("failed to send remind", slog.String("err", err.Error()))
-				continue
+			holdings[holding.ID] = holding
+		}
+
+		action, err := catchUpAction(task, holding, missed, func() (models.Event, error) {
+			if event, ok := events[holding.EventID]; ok {
+				return event, nil
 			}
-			err = rs.taskSvc.UpdateTaskAsReminded(task.ID)
+			event, err := rs.taskSvc.GetEventByID(ctx, holding.EventID)
 			if err != nil {
-				rs.logger.Error("failed to update task status", slog.String("err", err.Error()))
-				continue
+				return event, err
 			}
+			events[event.ID] = event
+			return event, nil
+		})
+		if err != nil {
+			rs.logger.Error("failed to get event info", slog.String("err", err.Error()))
+			rs.markFailed(stateCtx, task, "event_lookup", err)
+			continue
+		}
+		switch action {
+		case models.CatchUpPolicySummary:
+			overdueTasks[holding.ID] = append(overdueTasks[holding.ID], task)
+			continue
+		case models.CatchUpPolicySkip:
+			rs.logTransitError(rs.taskSvc.MarkTaskAsSkipped(stateCtx, task.ID, *task.IdempotencyKey))
+			metrics.RemindTasksSkipped.Inc()
+			continue
 		}
-		rs.logger.Info("cron job finished")
-	})
-	c.Start()
+
+		mention, err := mentionFor(holding)
+		if err != nil {
+			// 誰にも通知されないリマインドは送らない
+			rs.logger.Error("failed to resolve mentions", slog.String("err", err.Error()))
+			rs.markFailed(stateCtx, task, "mention", err)
+			continue
+		}
+		err = rs.sendRemind(ctx, task, holding, mention, blocking[task.ID], checklists[task.ID])
+		if err != nil {
+			rs.logger.Error("failed to send remind", slog.String("err", err.Error()))
+			rs.markPostFailed(stateCtx, task, err)
+			continue
+		}
+		rs.logTransitError(rs.taskSvc.MarkTaskAsSent(stateCtx, task.ID, *task.IdempotencyKey))
+		metrics.RemindTasksSent.Inc()
+	}
+
+	for holdingID, tasks := range overdueTasks {
+		holding := holdings[holdingID]
+		mention, err := mentionFor(holding)
+		if err != nil {
+			rs.logger.Error("failed to resolve mentions", slog.String("err", err.Error()))
+			for _, task := range tasks {
+				rs.markFailed(stateCtx, task, "mention", err)
+			}
+			continue
+		}
+		err = rs.sendOverdueSummary(ctx, tasks, holding, mention, blocking, checklists)
+		if err != nil {
+			rs.logger.Error("failed to send overdue summary", slog.String("err", err.Error()))
+			for _, task := range tasks {
+				rs.markPostFailed(stateCtx, task, err)
+			}
+			continue
+		}
+		for _, task := range tasks {
+			rs.logTransitError(rs.taskSvc.MarkTaskAsSent(stateCtx, task.ID, *task.IdempotencyKey))
+		}
+		metrics.RemindTasksSent.Add(float64(len(tasks)))
+	}
+
+	return nil
 }
 
-func (rs *RemindService) sendRemind(ctx context.Context, task models.Task, holding models.Holding) error {
-	content := fmt.Sprintf("%s %s", holding.Mention, task.Name)
+// 送信に失敗したタスクを、試行回数が上限に達するまでは再送対象に戻す
+func (rs *RemindService) markFailed(ctx context.Context, task models.Task, reason string, cause error) {
+	metrics.RemindTasksFailed.WithLabelValues(reason).Inc()
+	retry := task.Attempts < remindMaxAttempts
+	rs.logTransitError(rs.taskSvc.MarkTaskAsFailed(ctx, task.ID, *task.IdempotencyKey, cause, retry))
+}
+
+// 投稿に失敗したタスクを記録する
+// at-most-onceでは、traQに届いた可能性がある失敗（タイムアウトや5xxなど）は再送せずfailedにする
+func (rs *RemindService) markPostFailed(ctx context.Context, task models.Task, cause error) {
+	if rs.deliveryMode == DeliveryAtMostOnce && !postNotDelivered(cause) {
+		metrics.RemindTasksFailed.WithLabelValues("traq_post").Inc()
+		rs.logTransitError(rs.taskSvc.MarkTaskAsFailed(ctx, task.ID, *task.IdempotencyKey, cause, false))
+		return
+	}
+	rs.markFailed(ctx, task, "traq_post", cause)
+}
+
+// postNotDelivered は投稿の失敗が、traQにメッセージが届いていないと言い切れるものかを返す
+// 名前解決・接続の失敗と、traQが4xxで拒否した場合のみが該当する
+func postNotDelivered(err error) bool {
+	if errors.Is(err, errPostRejected) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (rs *RemindService) logTransitError(err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrTaskNotClaimed) {
+		rs.logger.Warn("task was taken over before its status was updated", slog.String("err", err.Error()))
+		return
+	}
+	rs.logger.Error("failed to update task status", slog.String("err", err.Error()))
+}
+
+// errNoMention は開催のメンションが1つも解決できず、誰にも通知されないことを表す
+var errNoMention = errors.New("no mention could be resolved")
+
+// mentionText はリマインドの先頭に付けるメンションを返す
+// ユーザー・グループを指定していない開催では旧形式の自由記述のメンションを使う
+// 指定したユーザー・グループが1つも解決できない場合も旧形式のメンションを使い、それも無ければエラーを返す
+func (rs *RemindService) mentionText(ctx context.Context, holding models.Holding) (string, error) {
+	if len(holding.Mentions) == 0 {
+		if holding.Mention == "" {
+			return "", fmt.Errorf("holding %d: %w", holding.ID, errNoMention)
+		}
+		return holding.Mention, nil
+	}
+
+	text, unresolved, err := rs.traqSvc.RenderMentions(ctx, holding.Mentions)
+	if err != nil {
+		if holding.Mention != "" {
+			rs.logger.Warn("failed to render mentions, using legacy mention",
+				slog.Int("holdingId", holding.ID), slog.String("err", err.Error()))
+			return holding.Mention, nil
+		}
+		return "", fmt.Errorf("render mentions of holding %d: %w", holding.ID, err)
+	}
+	if len(unresolved) > 0 {
+		ids := make([]string, len(unresolved))
+		for i, mention := range unresolved {
+			ids[i] = string(mention.Type) + ":" + mention.TargetID
+		}
+		rs.logger.Warn("mentioned users or groups no longer exist",
+			slog.Int("holdingId", holding.ID), slog.Any("mentions", ids))
+	}
+	if text == "" {
+		if holding.Mention != "" {
+			return holding.Mention, nil
+		}
+		return "", fmt.Errorf("holding %d: %w", holding.ID, errNoMention)
+	}
+	return text, nil
+}
+
+func (rs *RemindService) sendRemind(ctx context.Context, task models.Task, holding models.Holding, mention string, blockedBy []models.Task, checklist []models.ChecklistItem) error {
+	content := fmt.Sprintf("%s %s%s%s%s%s",
+		mention, task.Name, waitingOn(blockedBy), checklistProgress(checklist), unfinishedItems(checklist, ""), holdingPeriod(holding))
 	err := rs.traqSvc.PostMessage(ctx, holding.ChannelID, content)
 	if err != nil {
 		return err
@@ -67,3 +550,60 @@ func (rs *RemindService) sendRemind(ctx context.Context, task models.Task, holdi
 
 	return nil
 }
+
+func (rs *RemindService) sendOverdueSummary(ctx context.Context, tasks []models.Task, holding models.Holding, mention string, blocking map[int][]models.Task, checklists map[int][]models.ChecklistItem) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s 以下のタスクは既にリマインド日を過ぎています", mention)
+	for _, task := range tasks {
+		checklist := checklists[task.ID]
+		fmt.Fprintf(&sb, "\n- %s%s%s%s",
+			task.Name, waitingOn(blocking[task.ID]), checklistProgress(checklist), unfinishedItems(checklist, "  "))
+	}
+	sb.WriteString(holdingPeriod(holding))
+	return rs.traqSvc.PostMessage(ctx, holding.ChannelID, sb.String())
+}
+
+// holdingPeriod は複数日にわたる開催の期間をメッセージに添える形式にする
+func holdingPeriod(holding models.Holding) string {
+	if !holding.MultiDay() {
+		return ""
+	}
+	return fmt.Sprintf("\n%s（%s〜%s）", holding.Name, holding.Date.Format("1/2"), holding.LastDate().Format("1/2"))
+}
+
+// waitingOn は未完了の前提タスクをメッセージに添える形式にする
+func waitingOn(blockedBy []models.Task) string {
+	if len(blockedBy) == 0 {
+		return ""
+	}
+	names := make([]string, len(blockedBy))
+	for i, task := range blockedBy {
+		names[i] = task.Name
+	}
+	return fmt.Sprintf("（前提タスク待ち: %s）", strings.Join(names, "、"))
+}
+
+// checklistProgress はチェックリストの進捗をメッセージに添える形式にする
+func checklistProgress(checklist []models.ChecklistItem) string {
+	if len(checklist) == 0 {
+		return ""
+	}
+	done := 0
+	for _, item := range checklist {
+		if item.Done() {
+			done++
+		}
+	}
+	return fmt.Sprintf("（%d/%d 完了）", done, len(checklist))
+}
+
+// unfinishedItems は未完了のチェックリスト項目を1行ずつ列挙する
+func unfinishedItems(checklist []models.ChecklistItem, indent string) string {
+	var sb strings.Builder
+	for _, item := range checklist {
+		if !item.Done() {
+			fmt.Fprintf(&sb, "\n%s- [ ] %s", indent, item.Text)
+		}
+	}
+	return sb.String()
+}
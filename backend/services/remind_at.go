@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ========================================
+// リマインド日時（tasks.remind_at）の計算
+// ========================================
+
+// defaultRemindTime は時刻を指定していない日単位のタスクをリマインドする時刻
+const defaultRemindTime = "08:00:00"
+
+// anchorDateExpr はタスクの基準日を求めるSQL式（t: tasks, h: holdings）
+const anchorDateExpr = "CASE WHEN t.`anchor` = 'end' THEN COALESCE(h.`end_date`, h.`date`) ELSE h.`date` END"
+
+// remindAtExpr はタスクのリマインド日時を求めるSQL式（t: tasks, h: holdings）
+//   - offset_minutesがある場合: 基準日時からのオフセット
+//     基準日時は初日なら開始日時（開始時刻が無ければ0:00）、最終日ならその日の0:00
+//   - それ以外: 基準日のdays_before日前のremind_time（無ければdefaultRemindTime）
+const remindAtExpr = "CASE WHEN t.`offset_minutes` IS NOT NULL" +
+	" THEN TIMESTAMP(" + anchorDateExpr + ", CASE WHEN t.`anchor` = 'end' THEN '00:00:00' ELSE COALESCE(h.`start_time`, '00:00:00') END)" +
+	" + INTERVAL t.`offset_minutes` MINUTE" +
+	" ELSE TIMESTAMP(DATE_SUB(" + anchorDateExpr + ", INTERVAL t.`days_before` DAY), COALESCE(t.`remind_time`, '" + defaultRemindTime + "')) END"
+
+// refreshRemindAt は条件に合うタスクのリマインド日時を、開催とタスクの設定から計算し直す
+// 分単位のオフセットを持つタスクは、一覧や並び替えで使うdays_beforeもリマインド日時から求め直す
+func refreshRemindAt(ctx context.Context, execer sqlx.ExecerContext, where string, args ...any) error {
+	query := "UPDATE `tasks` t INNER JOIN `holdings` h ON t.`holding_id` = h.`id` SET" +
+		" t.`days_before` = CASE WHEN t.`offset_minutes` IS NOT NULL THEN DATEDIFF(" + anchorDateExpr + ", " + remindAtExpr + ") ELSE t.`days_before` END," +
+		" t.`remind_at` = " + remindAtExpr +
+		" WHERE " + where
+	_, err := execer.ExecContext(ctx, query, args...)
+	return err
+}
+
+// BackfillRemindAt はリマインド日時が未計算のタスクについて計算する
+// remind_at列の追加前に作られたタスクのため、起動時に実行する
+func (s *TaskService) BackfillRemindAt(ctx context.Context) error {
+	if err := refreshRemindAt(ctx, s.db, "t.`remind_at` IS NULL"); err != nil {
+		s.logger.Error("failed to backfill remind_at", slog.String("err", err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+	"github.com/robfig/cron/v3"
+)
+
+func TestMissedRuns(t *testing.T) {
+	schedule := cron.Every(time.Minute)
+	lastRunAt := time.Date(2024, 1, 10, 7, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantEmpty bool
+		wantTo    time.Time
+	}{
+		{name: "next run not yet due", now: lastRunAt.Add(30 * time.Second), wantEmpty: true},
+		{name: "one run missed", now: lastRunAt.Add(90 * time.Second), wantTo: lastRunAt.Add(time.Minute)},
+		{name: "down for an hour", now: lastRunAt.Add(time.Hour + 30*time.Second), wantTo: lastRunAt.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missedRuns(schedule, lastRunAt, tt.now)
+			if got.empty() != tt.wantEmpty {
+				t.Fatalf("missedRuns().empty() = %v, want %v", got.empty(), tt.wantEmpty)
+			}
+			if !tt.wantEmpty && !got.to.Equal(tt.wantTo) {
+				t.Errorf("missedRuns().to = %s, want %s", got.to, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestCatchUpAction(t *testing.T) {
+	holding := models.Holding{ID: 1, EventID: 1, Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)}
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// 2024-01-10 07:59 に最後に実行し、08:00から09:00まで停止していた
+	missed := missedWindow{
+		from: time.Date(2024, 1, 10, 7, 59, 0, 0, time.UTC),
+		to:   time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+	}
+	task := func(remindAt time.Time) models.Task {
+		return models.Task{HoldingID: holding.ID, DaysBefore: 10, RemindAt: &remindAt, CreatedAt: &createdAt}
+	}
+	duringDowntime := task(time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC))
+	beforeDowntime := task(time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC))
+	afterRestart := task(time.Date(2024, 1, 10, 9, 0, 30, 0, time.UTC))
+
+	tests := []struct {
+		name   string
+		task   models.Task
+		missed missedWindow
+		policy models.CatchUpPolicy
+		want   models.CatchUpPolicy
+	}{
+		{name: "missed during downtime, summary", task: duringDowntime, missed: missed, policy: models.CatchUpPolicySummary, want: models.CatchUpPolicySummary},
+		{name: "missed during downtime, skip", task: duringDowntime, missed: missed, policy: models.CatchUpPolicySkip, want: models.CatchUpPolicySkip},
+		{name: "missed during downtime, all", task: duringDowntime, missed: missed, policy: models.CatchUpPolicyAll, want: models.CatchUpPolicyAll},
+		{name: "due before the last run", task: beforeDowntime, missed: missed, policy: models.CatchUpPolicySkip, want: models.CatchUpPolicyAll},
+		{name: "due after restart", task: afterRestart, missed: missed, policy: models.CatchUpPolicySkip, want: models.CatchUpPolicyAll},
+		{name: "no downtime", task: duringDowntime, missed: missedWindow{}, policy: models.CatchUpPolicySkip, want: models.CatchUpPolicyAll},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := catchUpAction(tt.task, holding, tt.missed, func() (models.Event, error) {
+				return models.Event{ID: holding.EventID, CatchUpPolicy: tt.policy}, nil
+			})
+			if err != nil || got != tt.want {
+				t.Errorf("catchUpAction() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatchUpActionDoesNotLookUpEventForOnTimeTasks(t *testing.T) {
+	remindAt := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	task := models.Task{DaysBefore: 10, RemindAt: &remindAt}
+	got, err := catchUpAction(task, models.Holding{Date: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)}, missedWindow{}, func() (models.Event, error) {
+		return models.Event{}, errors.New("must not be called")
+	})
+	if err != nil || got != models.CatchUpPolicyAll {
+		t.Errorf("catchUpAction() = %q, %v, want %q", got, err, models.CatchUpPolicyAll)
+	}
+}
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -8,6 +12,9 @@ import (
 	"github.com/pirosiki197/event_reminder/models"
 )
 
+// 送信結果を反映しようとしたタスクが、既にこの送信の確保下にない
+var ErrTaskNotClaimed = errors.New("task is not claimed by this delivery")
+
 type TaskService struct {
 	db     *sqlx.DB
 	logger *slog.Logger
@@ -17,18 +24,31 @@ func NewTaskService(db *sqlx.DB, logger *slog.Logger) *TaskService {
 	return &TaskService{db: db, logger: logger}
 }
 
+func (s *TaskService) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
 // ========================================
 // Events (イベント) - CRUD
 // ========================================
 
-func (s *TaskService) CreateEvent(event models.Event) (int, error) {
-	tx, err := s.db.Beginx()
+func (s *TaskService) CreateEvent(ctx context.Context, event models.Event) (int, error) {
+	if err := validateEvent(event); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
 	defer tx.Rollback()
 
-	result, err := tx.Exec("INSERT INTO `events` (`name`) VALUES (?)", event.Name)
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO `events` (`name`, `catch_up_policy`, `blocked_policy`) VALUES (?, ?, ?)",
+		event.Name,
+		event.CatchUpPolicy,
+		event.BlockedPolicy,
+	)
 	if err != nil {
 		s.logger.Error("failed to create event", slog.String("err", err.Error()))
 		return 0, err
@@ -46,49 +66,109 @@ func (s *TaskService) CreateEvent(event models.Event) (int, error) {
 	return int(id), nil
 }
 
-func (s *TaskService) GetEventByID(id int) (models.Event, error) {
+func (s *TaskService) GetEventByID(ctx context.Context, id int) (models.Event, error) {
 	var event models.Event
-	err := s.db.Get(&event, "SELECT * FROM `events` WHERE `id` = ?", id)
-	return event, err
+	err := s.db.GetContext(ctx, &event, "SELECT * FROM `events` WHERE `id` = ? AND `deleted_at` IS NULL", id)
+	return event, notFound(err, "event", id)
 }
 
-func (s *TaskService) GetAllEvents() ([]models.Event, error) {
+// GetAllEvents はイベントの一覧を返す（includeArchivedがfalseならアーカイブしたイベントを除く）
+func (s *TaskService) GetAllEvents(ctx context.Context, includeArchived bool) ([]models.Event, error) {
+	query := "SELECT * FROM `events` WHERE `deleted_at` IS NULL"
+	if !includeArchived {
+		query += " AND `archived_at` IS NULL"
+	}
 	var events []models.Event
-	err := s.db.Select(&events, "SELECT * FROM `events` ORDER BY `id` DESC")
+	err := s.db.SelectContext(ctx, &events, query+" ORDER BY `id` DESC")
 	if events == nil {
 		events = []models.Event{}
 	}
 	return events, err
 }
 
-func (s *TaskService) UpdateEvent(id int, event models.Event) error {
-	tx, err := s.db.Beginx()
+// UpdateEvent はイベントを更新し、更新後のイベント（ETag用の版を含む）を返す
+func (s *TaskService) UpdateEvent(ctx context.Context, id int, event models.Event) (models.Event, error) {
+	if err := validateEvent(event); err != nil {
+		return models.Event{}, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return err
+		return models.Event{}, err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("UPDATE `events` SET `name` = ? WHERE `id` = ?", event.Name, id)
+	if err := lockVersion(ctx, tx, "events", "event", id, event.Version); err != nil {
+		return models.Event{}, err
+	}
+
+	// CatchUpPolicy, BlockedPolicyが空の場合は既存の値を維持する
+	var catchUpPolicy *models.CatchUpPolicy
+	if event.CatchUpPolicy != "" {
+		catchUpPolicy = &event.CatchUpPolicy
+	}
+	var blockedPolicy *models.BlockedPolicy
+	if event.BlockedPolicy != "" {
+		blockedPolicy = &event.BlockedPolicy
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE `events` SET `name` = ?, `catch_up_policy` = COALESCE(?, `catch_up_policy`), `blocked_policy` = COALESCE(?, `blocked_policy`), `version` = `version` + 1 WHERE `id` = ? AND `deleted_at` IS NULL",
+		event.Name,
+		catchUpPolicy,
+		blockedPolicy,
+		id,
+	)
 	if err != nil {
 		s.logger.Error("failed to update event", slog.String("err", err.Error()))
-		return err
+		return models.Event{}, err
+	}
+	if err := requireAffected(result, "event", id); err != nil {
+		return models.Event{}, err
 	}
 
-	return tx.Commit()
+	var updated models.Event
+	if err := tx.GetContext(ctx, &updated, "SELECT * FROM `events` WHERE `id` = ?", id); err != nil {
+		return models.Event{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Event{}, err
+	}
+	return updated, nil
 }
 
-func (s *TaskService) DeleteEvent(id int) error {
-	tx, err := s.db.Beginx()
+// DeleteEvent はイベントを開催とタスクごとゴミ箱に入れる
+func (s *TaskService) DeleteEvent(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("DELETE FROM `events` WHERE `id` = ?", id)
+	// 一緒にゴミ箱に入れたものを復元時に見分けられるように、同じ時刻を記録する
+	now := trashTime()
+	result, err := tx.ExecContext(ctx, "UPDATE `events` SET `deleted_at` = ? WHERE `id` = ? AND `deleted_at` IS NULL", now, id)
 	if err != nil {
 		s.logger.Error("failed to delete event", slog.String("err", err.Error()))
 		return err
 	}
+	if err := requireAffected(result, "event", id); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `tasks` t INNER JOIN `holdings` h ON t.`holding_id` = h.`id` SET t.`deleted_at` = ? WHERE h.`event_id` = ? AND t.`deleted_at` IS NULL",
+		now, id,
+	)
+	if err != nil {
+		s.logger.Error("failed to delete tasks of event", slog.String("err", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE `holdings` SET `deleted_at` = ? WHERE `event_id` = ? AND `deleted_at` IS NULL", now, id)
+	if err != nil {
+		s.logger.Error("failed to delete holdings of event", slog.String("err", err.Error()))
+		return err
+	}
 
 	return tx.Commit()
 }
@@ -97,60 +177,39 @@ func (s *TaskService) DeleteEvent(id int) error {
 // Holdings (開催) - CRUD
 // ========================================
 
-func (s *TaskService) CreateHolding(holding models.Holding) (int, error) {
-	tx, err := s.db.Beginx()
-	if err != nil {
+func (s *TaskService) CreateHolding(ctx context.Context, holding models.Holding) (int, error) {
+	if err := validateHolding(holding); err != nil {
 		return 0, err
 	}
-	defer tx.Rollback()
 
-	result, err := tx.Exec(
-		"INSERT INTO `holdings` (`event_id`, `name`, `date`, `channel_id`, `mention`) VALUES (?, ?, ?, ?, ?)",
-		holding.EventID,
-		holding.Name,
-		holding.Date,
-		holding.ChannelID,
-		holding.Mention,
-	)
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
-		s.logger.Error("failed to create holding", slog.String("err", err.Error()))
 		return 0, err
 	}
+	defer tx.Rollback()
 
-	holdingID, err := result.LastInsertId()
+	holdingID, err := s.insertHolding(ctx, tx, holding)
 	if err != nil {
 		return 0, err
 	}
 
 	// 同じイベントIDの最新のholdingからタスクをコピー
 	var latestHoldingID int
-	err = tx.Get(&latestHoldingID,
-		"SELECT `id` FROM `holdings` WHERE `event_id` = ? AND `id` != ? ORDER BY `date` DESC LIMIT 1",
+	err = tx.GetContext(ctx, &latestHoldingID,
+		"SELECT `id` FROM `holdings` WHERE `event_id` = ? AND `id` != ? AND `deleted_at` IS NULL ORDER BY `date` DESC LIMIT 1",
 		holding.EventID,
 		holdingID,
 	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		s.logger.Error("failed to get latest holding", slog.String("err", err.Error()))
+		return 0, err
+	}
 
 	// 最新のholdingが存在する場合のみタスクをコピー
 	if err == nil {
-		var tasks []models.Task
-		err = tx.Select(&tasks, "SELECT * FROM `tasks` WHERE `holding_id` = ?", latestHoldingID)
-		if err != nil {
-			s.logger.Error("failed to get tasks from latest holding", slog.String("err", err.Error()))
+		if err := s.copyTasks(ctx, tx, latestHoldingID, holdingID); err != nil {
 			return 0, err
 		}
-		for _, task := range tasks {
-			_, err = tx.Exec(
-				"INSERT INTO `tasks` (`holding_id`, `name`, `days_before`, `description`) VALUES (?, ?, ?, ?)",
-				holdingID,
-				task.Name,
-				task.DaysBefore,
-				task.Description,
-			)
-			if err != nil {
-				s.logger.Error("failed to copy task to holding", slog.String("err", err.Error()))
-				return 0, err
-			}
-		}
 	}
 	// 最新のholdingが存在しない場合は何もせず（タスクなしで作成）
 
@@ -158,38 +217,168 @@ func (s *TaskService) CreateHolding(holding models.Holding) (int, error) {
 		return 0, err
 	}
 
-	return int(holdingID), nil
+	return holdingID, nil
 }
 
-func (s *TaskService) GetHoldingByID(id int) (models.Holding, error) {
-	var holding models.Holding
-	err := s.db.Get(&holding, "SELECT * FROM `holdings` WHERE `id` = ?", id)
-	return holding, err
+// CloneHolding はsourceIDの開催のタスクを全て引き継いだ開催を作成する
+// 送信状態と完了状態は引き継がない
+func (s *TaskService) CloneHolding(ctx context.Context, sourceID int, holding models.Holding) (int, error) {
+	if err := validateHolding(holding); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// コピーの途中でコピー元の開催が削除されないようにする
+	var source models.Holding
+	err = tx.GetContext(ctx, &source, "SELECT * FROM `holdings` WHERE `id` = ? AND `deleted_at` IS NULL FOR SHARE", sourceID)
+	if err != nil {
+		return 0, notFound(err, "holding", sourceID)
+	}
+
+	holdingID, err := s.insertHolding(ctx, tx, holding)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.copyTasks(ctx, tx, source.ID, holdingID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return holdingID, nil
+}
+
+func (s *TaskService) insertHolding(ctx context.Context, tx *sqlx.Tx, holding models.Holding) (int, error) {
+	// ゴミ箱にあるイベントは外部キーでは弾けないので確認する
+	var count int
+	err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM `events` WHERE `id` = ? AND `deleted_at` IS NULL FOR SHARE", holding.EventID)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, newValidationError("eventId", "referenced resource does not exist")
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO `holdings` (`event_id`, `name`, `date`, `start_time`, `end_date`, `channel_id`, `mention`) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		holding.EventID,
+		holding.Name,
+		holding.Date,
+		holding.StartTime,
+		holding.EndDate,
+		holding.ChannelID,
+		holding.Mention,
+	)
+	if err != nil {
+		s.logger.Error("failed to create holding", slog.String("err", err.Error()))
+		return 0, translateDBError(err, "eventId")
+	}
+
+	holdingID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.setMentions(ctx, tx, int(holdingID), holding.Mentions); err != nil {
+		return 0, err
+	}
+	return int(holdingID), nil
 }
 
-func (s *TaskService) GetHoldingsByEventID(eventID int) ([]models.Holding, error) {
-	var holdings []models.Holding
-	err := s.db.Select(&holdings, "SELECT * FROM `holdings` WHERE `event_id` = ? ORDER BY `date` DESC", eventID)
-	return holdings, err
+// copyTasks はコピー元の開催のタスクを、前提タスクとチェックリストを含めてコピー先の開催に複製する
+// 送信状態と完了状態は引き継がず、リマインド日時はコピー先の開催の日程から計算する
+func (s *TaskService) copyTasks(ctx context.Context, tx *sqlx.Tx, srcHoldingID int, dstHoldingID int) error {
+	var tasks []models.Task
+	err := tx.SelectContext(ctx, &tasks, "SELECT * FROM `tasks` WHERE `holding_id` = ? AND `deleted_at` IS NULL", srcHoldingID)
+	if err != nil {
+		s.logger.Error("failed to get tasks to copy", slog.String("err", err.Error()))
+		return err
+	}
+	now := time.Now()
+	idMap := make(map[int]int, len(tasks))
+	for _, task := range tasks {
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO `tasks` (`holding_id`, `name`, `anchor`, `days_before`, `offset_minutes`, `remind_time`, `description`, `created_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			dstHoldingID,
+			task.Name,
+			task.Anchor,
+			task.DaysBefore,
+			task.OffsetMinutes,
+			task.RemindTime,
+			task.Description,
+			now,
+		)
+		if err != nil {
+			s.logger.Error("failed to copy task to holding", slog.String("err", err.Error()))
+			return err
+		}
+		newID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		idMap[task.ID] = int(newID)
+	}
+	if err := copyDependencies(ctx, tx, idMap); err != nil {
+		s.logger.Error("failed to copy task dependencies", slog.String("err", err.Error()))
+		return err
+	}
+	if err := copyChecklistItems(ctx, tx, idMap); err != nil {
+		s.logger.Error("failed to copy checklist items", slog.String("err", err.Error()))
+		return err
+	}
+	if err := refreshRemindAt(ctx, tx, "t.`holding_id` = ?", dstHoldingID); err != nil {
+		s.logger.Error("failed to compute remind_at", slog.String("err", err.Error()))
+		return err
+	}
+	return nil
 }
 
-func (s *TaskService) GetAllHoldings() ([]models.Holding, error) {
-	var holdings []models.Holding
-	err := s.db.Select(&holdings, "SELECT * FROM `holdings` ORDER BY `date` DESC")
-	return holdings, err
+func (s *TaskService) GetHoldingByID(ctx context.Context, id int) (models.Holding, error) {
+	var holding models.Holding
+	err := s.db.GetContext(ctx, &holding, "SELECT * FROM `holdings` WHERE `id` = ? AND `deleted_at` IS NULL", id)
+	if err != nil {
+		return holding, notFound(err, "holding", id)
+	}
+	holdings := []models.Holding{holding}
+	if err := s.attachMentions(ctx, holdings); err != nil {
+		return holding, err
+	}
+	return holdings[0], nil
 }
 
-func (s *TaskService) UpdateHolding(id int, holding models.Holding) error {
-	tx, err := s.db.Beginx()
+func (s *TaskService) UpdateHolding(ctx context.Context, id int, holding models.Holding) error {
+	if err := validateHolding(holding); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec(
-		"UPDATE `holdings` SET `name` = ?, `date` = ?, `channel_id` = ?, `mention` = ? WHERE `id` = ?",
+	if err := lockVersion(ctx, tx, "holdings", "holding", id, holding.Version); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE `holdings` SET `name` = ?, `date` = ?, `start_time` = ?, `end_date` = ?,"+
+			// 通知先チャンネルを変えた場合は確認の結果を取り消す（チャンネルは保存前に確認している）
+			" `channel_status` = IF(`channel_id` = ?, `channel_status`, 'ok'), `channel_checked_at` = IF(`channel_id` = ?, `channel_checked_at`, NULL),"+
+			" `channel_id` = ?, `mention` = ?, `version` = `version` + 1 WHERE `id` = ? AND `deleted_at` IS NULL",
 		holding.Name,
 		holding.Date,
+		holding.StartTime,
+		holding.EndDate,
+		holding.ChannelID,
+		holding.ChannelID,
 		holding.ChannelID,
 		holding.Mention,
 		id,
@@ -198,22 +387,49 @@ func (s *TaskService) UpdateHolding(id int, holding models.Holding) error {
 		s.logger.Error("failed to update holding", slog.String("err", err.Error()))
 		return err
 	}
+	if err := requireAffected(result, "holding", id); err != nil {
+		return err
+	}
+
+	// Mentionsがnilの場合はメンションを変更しない
+	if holding.Mentions != nil {
+		if err := s.setMentions(ctx, tx, id, holding.Mentions); err != nil {
+			return err
+		}
+	}
+
+	// 開催日時が変わった場合に備えてタスクのリマインド日時を計算し直す
+	if err := refreshRemindAt(ctx, tx, "t.`holding_id` = ?", id); err != nil {
+		s.logger.Error("failed to compute remind_at", slog.String("err", err.Error()))
+		return err
+	}
 
 	return tx.Commit()
 }
 
-func (s *TaskService) DeleteHolding(id int) error {
-	tx, err := s.db.Beginx()
+// DeleteHolding は開催をタスクごとゴミ箱に入れる
+func (s *TaskService) DeleteHolding(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("DELETE FROM `holdings` WHERE `id` = ?", id)
+	now := trashTime()
+	result, err := tx.ExecContext(ctx, "UPDATE `holdings` SET `deleted_at` = ? WHERE `id` = ? AND `deleted_at` IS NULL", now, id)
 	if err != nil {
 		s.logger.Error("failed to delete holding", slog.String("err", err.Error()))
 		return err
 	}
+	if err := requireAffected(result, "holding", id); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE `tasks` SET `deleted_at` = ? WHERE `holding_id` = ? AND `deleted_at` IS NULL", now, id)
+	if err != nil {
+		s.logger.Error("failed to delete tasks of holding", slog.String("err", err.Error()))
+		return err
+	}
 
 	return tx.Commit()
 }
@@ -222,19 +438,55 @@ func (s *TaskService) DeleteHolding(id int) error {
 // Tasks (開催タスク / HoldingTasks) - CRUD
 // ========================================
 
-func (s *TaskService) CreateTask(task models.Task) (int, error) {
-	tx, err := s.db.Beginx()
+func (s *TaskService) CreateTask(ctx context.Context, task models.Task) (int, error) {
+	if err := validateTask(task); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
 	defer tx.Rollback()
 
-	result, err := tx.Exec(
-		"INSERT INTO `tasks` (`holding_id`, `name`, `days_before`, `description`) VALUES (?, ?, ?, ?)",
+	id, err := s.insertTask(ctx, tx, task)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// insertTask はトランザクション内でタスクを作成し、リマインド日時と前提タスクを設定する
+func (s *TaskService) insertTask(ctx context.Context, tx *sqlx.Tx, task models.Task) (int, error) {
+	if task.Anchor == "" {
+		task.Anchor = models.TaskAnchorStart
+	}
+
+	// ゴミ箱にある開催は外部キーでは弾けないので確認する
+	var count int
+	err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM `holdings` WHERE `id` = ? AND `deleted_at` IS NULL FOR SHARE", task.HoldingID)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("holding %d: %w", task.HoldingID, ErrNotFound)
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO `tasks` (`holding_id`, `name`, `anchor`, `days_before`, `offset_minutes`, `remind_time`, `description`, `created_at`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
 		task.HoldingID,
 		task.Name,
+		task.Anchor,
 		task.DaysBefore,
+		task.OffsetMinutes,
+		task.RemindTime,
 		task.Description,
+		time.Now(),
 	)
 	if err != nil {
 		s.logger.Error("failed to create task", slog.String("err", err.Error()))
@@ -246,80 +498,267 @@ func (s *TaskService) CreateTask(task models.Task) (int, error) {
 		return 0, err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := refreshRemindAt(ctx, tx, "t.`id` = ?", id); err != nil {
+		s.logger.Error("failed to compute remind_at", slog.String("err", err.Error()))
 		return 0, err
 	}
 
+	if len(task.DependsOn) > 0 {
+		task.ID = int(id)
+		if err := setDependencies(ctx, tx, task); err != nil {
+			return 0, err
+		}
+	}
+
 	return int(id), nil
 }
 
-func (s *TaskService) GetTaskByID(id int) (models.Task, error) {
+func (s *TaskService) GetTaskByID(ctx context.Context, id int) (models.Task, error) {
 	var task models.Task
-	err := s.db.Get(&task, "SELECT * FROM `tasks` WHERE `id` = ?", id)
-	return task, err
+	err := s.db.GetContext(ctx, &task, "SELECT * FROM `tasks` WHERE `id` = ? AND `deleted_at` IS NULL", id)
+	if err != nil {
+		return task, notFound(err, "task", id)
+	}
+	tasks := []models.Task{task}
+	if err := s.attachDependencies(ctx, tasks); err != nil {
+		return task, err
+	}
+	return tasks[0], nil
 }
 
-func (s *TaskService) GetTasksByHoldingID(holdingID int) ([]models.Task, error) {
+func (s *TaskService) GetTasksByHoldingID(ctx context.Context, holdingID int) ([]models.Task, error) {
 	var tasks []models.Task
-	err := s.db.Select(&tasks, "SELECT * FROM `tasks` WHERE `holding_id` = ? ORDER BY `days_before` DESC", holdingID)
-	return tasks, err
+	err := s.db.SelectContext(ctx, &tasks, "SELECT * FROM `tasks` WHERE `holding_id` = ? AND `deleted_at` IS NULL ORDER BY `days_before` DESC", holdingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachDependencies(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
-func (s *TaskService) GetAllTasks() ([]models.Task, error) {
-	var tasks []models.Task
-	err := s.db.Select(&tasks, "SELECT * FROM `tasks` ORDER BY `id` DESC")
-	return tasks, err
-}
+func (s *TaskService) UpdateTask(ctx context.Context, id int, task models.Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
 
-func (s *TaskService) UpdateTask(id int, task models.Task) error {
-	tx, err := s.db.Beginx()
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec(
-		"UPDATE `tasks` SET `name` = ?, `days_before` = ?, `description` = ? WHERE `id` = ?",
+	if err := s.updateTask(ctx, tx, id, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateTask はトランザクション内でタスクを更新し、リマインド日時と前提タスクを設定し直す
+// task.Versionが0でなければ、現在の版と一致する場合のみ更新する
+func (s *TaskService) updateTask(ctx context.Context, tx *sqlx.Tx, id int, task models.Task) error {
+	if err := lockVersion(ctx, tx, "tasks", "task", id, task.Version); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE `tasks` SET `name` = ?, `anchor` = ?, `days_before` = ?, `offset_minutes` = ?, `remind_time` = ?, `description` = ?, `completed_at` = ?, `version` = `version` + 1 WHERE `id` = ? AND `deleted_at` IS NULL",
 		task.Name,
+		task.Anchor,
 		task.DaysBefore,
+		task.OffsetMinutes,
+		task.RemindTime,
 		task.Description,
+		task.CompletedAt,
 		id,
 	)
 	if err != nil {
 		s.logger.Error("failed to update task", slog.String("err", err.Error()))
 		return err
 	}
+	if err := requireAffected(result, "task", id); err != nil {
+		return err
+	}
 
-	return tx.Commit()
+	if err := refreshRemindAt(ctx, tx, "t.`id` = ?", id); err != nil {
+		s.logger.Error("failed to compute remind_at", slog.String("err", err.Error()))
+		return err
+	}
+
+	// DependsOnがnilの場合は前提タスクを変更しない
+	if task.DependsOn != nil {
+		task.ID = id
+		if err := setDependencies(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (s *TaskService) DeleteTask(id int) error {
-	_, err := s.db.Exec("DELETE FROM `tasks` WHERE `id` = ?", id)
+// DeleteTask はタスクをゴミ箱に入れる
+func (s *TaskService) DeleteTask(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, "UPDATE `tasks` SET `deleted_at` = ? WHERE `id` = ? AND `deleted_at` IS NULL", trashTime(), id)
 	if err != nil {
 		s.logger.Error("failed to delete task", slog.String("err", err.Error()))
+		return err
 	}
-	return err
+	return requireAffected(result, "task", id)
 }
 
 // ========================================
 // リマインド機能用のヘルパー
 // ========================================
 
-// Bot用: リマインドすべきタスクを取得
-func (s *TaskService) GetTasksToRemind() ([]models.Task, error) {
+// Bot用: リマインドすべきタスクを取得し、このインスタンスが送信する分として確保する
+// 確保したタスクはsendingに遷移し、送信ごとに一意なidempotency_keyが割り当てられる
+// 他のインスタンスが確保処理中の行はSKIP LOCKEDによって除外される
+func (s *TaskService) ClaimTasksToRemind(ctx context.Context, owner string, passID string) ([]models.Task, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	now := time.Now()
-	var tasks []models.Task
+	var ids []int
+	// アーカイブしたイベントのタスクは対象外にする
+	// 前提タスクの完了を待つイベントでは、未完了の前提タスクがあるタスクを対象外にする
 	query := `
-		SELECT t.*
+		SELECT t.id
 		FROM tasks t
 		INNER JOIN holdings h ON t.holding_id = h.id
-		WHERE DATE_SUB(h.date, INTERVAL t.days_before DAY) <= ? AND t.reminded = false
+		INNER JOIN events e ON h.event_id = e.id
+		WHERE t.remind_at <= ? AND t.status = 'pending' AND t.deleted_at IS NULL AND e.archived_at IS NULL
+			AND NOT (e.blocked_policy = 'defer' AND EXISTS (
+				SELECT 1 FROM task_dependencies d
+				INNER JOIN tasks p ON d.depends_on_task_id = p.id
+				WHERE d.task_id = t.id AND p.completed_at IS NULL AND p.deleted_at IS NULL
+			))
+		FOR UPDATE OF t SKIP LOCKED
 	`
-	err := s.db.Select(&tasks, query, now)
-	return tasks, err
+	err = tx.SelectContext(ctx, &ids, query, now)
+	if err != nil {
+		s.logger.Error("failed to select tasks to remind", slog.String("err", err.Error()))
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []models.Task{}, nil
+	}
+
+	query, args, err := sqlx.In(
+		"UPDATE `tasks` SET `status` = 'sending', `attempts` = `attempts` + 1, `claimed_by` = ?, `claimed_at` = ?, `idempotency_key` = CONCAT(?, '-', `id`) WHERE `id` IN (?)",
+		owner, now, passID, ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		s.logger.Error("failed to claim tasks", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	var tasks []models.Task
+	query, args, err = sqlx.In("SELECT * FROM `tasks` WHERE `id` IN (?)", ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.SelectContext(ctx, &tasks, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
-func (s *TaskService) UpdateTaskAsReminded(id int) error {
-	_, err := s.db.Exec("UPDATE `tasks` SET `reminded` = 1 WHERE `id` = ?", id)
+// sendingのタスクを送信結果に応じて遷移させる
+// idempotency_keyが一致しない場合（復旧処理で別の送信に引き継がれた場合など）は何もしない
+func (s *TaskService) transitSendingTask(ctx context.Context, id int, key string, status models.TaskStatus, lastError *string) error {
+	var sentAt *time.Time
+	if status == models.TaskStatusSent {
+		now := time.Now()
+		sentAt = &now
+	}
+
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE `tasks` SET `status` = ?, `last_error` = ?, `sent_at` = COALESCE(?, `sent_at`), `claimed_by` = NULL, `claimed_at` = NULL WHERE `id` = ? AND `status` = 'sending' AND `idempotency_key` = ?",
+		status,
+		lastError,
+		sentAt,
+		id,
+		key,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTaskNotClaimed
+	}
+	return nil
+}
+
+func (s *TaskService) MarkTaskAsSent(ctx context.Context, id int, key string) error {
+	return s.transitSendingTask(ctx, id, key, models.TaskStatusSent, nil)
+}
+
+// 作成時点で期限切れだったタスクをリマインドせずに完了扱いにする
+func (s *TaskService) MarkTaskAsSkipped(ctx context.Context, id int, key string) error {
+	return s.transitSendingTask(ctx, id, key, models.TaskStatusSkipped, nil)
+}
+
+// 送信に失敗したタスクを記録する。retryがtrueなら次回の実行で再送される
+func (s *TaskService) MarkTaskAsFailed(ctx context.Context, id int, key string, cause error, retry bool) error {
+	status := models.TaskStatusFailed
+	if retry {
+		status = models.TaskStatusPending
+	}
+	msg := cause.Error()
+	return s.transitSendingTask(ctx, id, key, status, &msg)
+}
+
+// sendingのまま取り残されたタスクを復旧する
+// 送信中にプロセスが落ちた場合、送信済みかどうかは分からないため、
+// 再送する（pending）か諦める（failed）かを呼び出し側が決める
+func (s *TaskService) ReconcileStuckTasks(ctx context.Context, claimedBefore time.Time, to models.TaskStatus) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE `tasks` SET `status` = ?, `last_error` = 'interrupted while sending', `claimed_by` = NULL, `claimed_at` = NULL WHERE `status` = 'sending' AND `claimed_at` < ?",
+		to,
+		claimedBefore,
+	)
+	if err != nil {
+		s.logger.Error("failed to reconcile stuck tasks", slog.String("err", err.Error()))
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// 旧フラグ（reminded / skipped）をstatusに移行する
+// 移行後はフラグを下ろすため、何度実行しても結果は変わらない
+func (s *TaskService) MigrateLegacyTaskStatus(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE `tasks` SET `status` = IF(`skipped`, 'skipped', 'sent'), `reminded` = false, `skipped` = false WHERE `reminded` OR `skipped`")
+	return err
+}
+
+// ========================================
+// スケジューラの実行履歴
+// ========================================
+
+// 最後に成功したジョブの実行時刻を取得（未実行の場合はsql.ErrNoRows）
+func (s *TaskService) GetLastRunAt(ctx context.Context, name string) (time.Time, error) {
+	var lastRunAt time.Time
+	err := s.db.GetContext(ctx, &lastRunAt, "SELECT `last_run_at` FROM `scheduler_runs` WHERE `name` = ?", name)
+	return lastRunAt, err
+}
+
+func (s *TaskService) UpdateLastRunAt(ctx context.Context, name string, runAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO `scheduler_runs` (`name`, `last_run_at`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `last_run_at` = VALUES(`last_run_at`)",
+		name,
+		runAt,
+	)
 	return err
 }
@@ -2,17 +2,27 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/motoki317/sc"
+	"github.com/pirosiki197/event_reminder/metrics"
+	"github.com/pirosiki197/event_reminder/models"
 	"github.com/traPtitech/go-traq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TraQService struct {
-	client           *traq.APIClient
-	channelListCache *sc.Cache[struct{}, []TraQChannel]
+	client             *traq.APIClient
+	channelListCache   *sc.Cache[struct{}, channelIndex]
+	userListCache      *sc.Cache[struct{}, []TraQUser]
+	userGroupListCache *sc.Cache[struct{}, []TraQUserGroup]
 }
 
 func NewTraQService(client *traq.APIClient) *TraQService {
@@ -20,6 +30,11 @@ func NewTraQService(client *traq.APIClient) *TraQService {
 		client: client,
 	}
 	s.channelListCache = sc.NewMust(s.getChannelList, 5*time.Minute, 10*time.Minute)
+	s.userListCache = sc.NewMust(s.getUserList, 5*time.Minute, 10*time.Minute)
+	s.userGroupListCache = sc.NewMust(s.getUserGroupList, 5*time.Minute, 10*time.Minute)
+	metrics.RegisterCacheStats("traq_channel_list", s.channelListCache.Stats)
+	metrics.RegisterCacheStats("traq_user_list", s.userListCache.Stats)
+	metrics.RegisterCacheStats("traq_user_group_list", s.userGroupListCache.Stats)
 	return s
 }
 
@@ -28,15 +43,60 @@ type TraQChannel struct {
 	Name string `json:"name"`
 }
 
+// channelIndex はキャッシュするチャンネルの情報
+type channelIndex struct {
+	// アーカイブされていないチャンネルのパス付きの一覧
+	list []TraQChannel
+	// アーカイブされたものを含む全ての公開チャンネル
+	byID map[string]traq.Channel
+}
+
 func (s *TraQService) GetChannelList(ctx context.Context) ([]TraQChannel, error) {
-	return s.channelListCache.Get(ctx, struct{}{})
+	index, err := s.channelListCache.Get(ctx, struct{}{})
+	return index.list, err
 }
 
-func (s *TraQService) getChannelList(ctx context.Context, _ struct{}) ([]TraQChannel, error) {
+// GetChannelStatus はチャンネルが投稿できる状態かを返す
+// キャッシュに無いチャンネルは作成されたばかりの可能性があるので、traQに直接問い合わせる
+func (s *TraQService) GetChannelStatus(ctx context.Context, channelID string) (models.ChannelStatus, error) {
+	index, err := s.channelListCache.Get(ctx, struct{}{})
+	if err != nil {
+		return "", err
+	}
+	channel, ok := index.byID[channelID]
+	if !ok {
+		ctx, done := instrumentTraQ(ctx, "GetChannel", attribute.String("traq.channel_id", channelID))
+		c, res, err := s.client.ChannelApi.GetChannel(ctx, channelID).Execute()
+		if res != nil && (res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusBadRequest) {
+			// 存在しないIDやUUIDでないIDは呼び出しの失敗として数えない
+			done(nil)
+			return models.ChannelStatusNotFound, nil
+		}
+		done(err)
+		if err != nil {
+			return "", err
+		}
+		channel = *c
+	}
+	if channel.Archived {
+		return models.ChannelStatusArchived, nil
+	}
+	return models.ChannelStatusOK, nil
+}
+
+func (s *TraQService) getChannelList(ctx context.Context, _ struct{}) (channelIndex, error) {
+	ctx, done := instrumentTraQ(ctx, "GetChannels")
 	allChannels, _, err := s.client.ChannelApi.GetChannels(ctx).Execute()
+	done(err)
 	if err != nil {
-		return nil, err
+		return channelIndex{}, err
+	}
+
+	index := channelIndex{byID: make(map[string]traq.Channel, len(allChannels.Public))}
+	for _, c := range allChannels.Public {
+		index.byID[c.Id] = c
 	}
+
 	channels := slices.DeleteFunc(allChannels.Public, func(c traq.Channel) bool { return c.Archived })
 
 	channelByID := make(map[string]traq.Channel, len(channels))
@@ -59,8 +119,9 @@ func (s *TraQService) getChannelList(ctx context.Context, _ struct{}) ([]TraQCha
 		})
 		buildChannelList(r, r.Name, channelByID, &res)
 	}
+	index.list = res
 
-	return res, nil
+	return index, nil
 }
 
 func buildChannelList(parent traq.Channel, path string, channelByID map[string]traq.Channel, res *[]TraQChannel) {
@@ -78,17 +139,240 @@ func buildChannelList(parent traq.Channel, path string, channelByID map[string]t
 	}
 }
 
+type TraQUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Bot         bool   `json:"bot"`
+}
+
+type TraQUserGroup struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// GetUserList は凍結されていないユーザーの一覧を返す
+func (s *TraQService) GetUserList(ctx context.Context) ([]TraQUser, error) {
+	return s.userListCache.Get(ctx, struct{}{})
+}
+
+func (s *TraQService) getUserList(ctx context.Context, _ struct{}) ([]TraQUser, error) {
+	ctx, done := instrumentTraQ(ctx, "GetUsers")
+	users, _, err := s.client.UserApi.GetUsers(ctx).Execute()
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]TraQUser, len(users))
+	for i, u := range users {
+		res[i] = TraQUser{
+			ID:          u.Id,
+			Name:        u.Name,
+			DisplayName: u.DisplayName,
+			Bot:         u.Bot,
+		}
+	}
+	return res, nil
+}
+
+// GetUserGroupList はユーザーグループの一覧を返す
+func (s *TraQService) GetUserGroupList(ctx context.Context) ([]TraQUserGroup, error) {
+	return s.userGroupListCache.Get(ctx, struct{}{})
+}
+
+func (s *TraQService) getUserGroupList(ctx context.Context, _ struct{}) ([]TraQUserGroup, error) {
+	ctx, done := instrumentTraQ(ctx, "GetUserGroups")
+	groups, _, err := s.client.GroupApi.GetUserGroups(ctx).Execute()
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]TraQUserGroup, len(groups))
+	for i, g := range groups {
+		res[i] = TraQUserGroup{
+			ID:          g.Id,
+			Name:        g.Name,
+			Description: g.Description,
+		}
+	}
+	return res, nil
+}
+
+// mentionNames はメンションできるユーザー・グループのIDから名前を引く表を返す
+func (s *TraQService) mentionNames(ctx context.Context) (map[models.HoldingMention]string, error) {
+	users, err := s.GetUserList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.GetUserGroupList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[models.HoldingMention]string, len(users)+len(groups))
+	for _, u := range users {
+		names[models.HoldingMention{Type: models.MentionTypeUser, TargetID: u.ID}] = u.Name
+	}
+	for _, g := range groups {
+		names[models.HoldingMention{Type: models.MentionTypeGroup, TargetID: g.ID}] = g.Name
+	}
+	return names, nil
+}
+
+// FindUnknownMentions はtraQに存在しないユーザー・グループを指すメンションの位置を返す
+func (s *TraQService) FindUnknownMentions(ctx context.Context, mentions []models.HoldingMention) ([]int, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	names, err := s.mentionNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var unknown []int
+	for i, mention := range mentions {
+		if _, ok := names[models.HoldingMention{Type: mention.Type, TargetID: mention.TargetID}]; !ok {
+			unknown = append(unknown, i)
+		}
+	}
+	return unknown, nil
+}
+
+// ResolveLegacyMention は旧形式の自由記述のメンション（"@alice @staff"）をユーザー・グループのメンションにする
+// 名前の分からないものが含まれる場合はfalseを返す
+func (s *TraQService) ResolveLegacyMention(ctx context.Context, text string) ([]models.HoldingMention, bool, error) {
+	names, err := s.mentionNames(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	mentions, ok := resolveLegacyMention(names, text)
+	return mentions, ok, nil
+}
+
+// resolveLegacyMention はIDから名前を引く表を逆に引いて、空白で区切られた名前をメンションにする
+func resolveLegacyMention(names map[models.HoldingMention]string, text string) ([]models.HoldingMention, bool) {
+	ids := make(map[string]models.HoldingMention, len(names))
+	for mention, name := range names {
+		// ユーザーとグループで名前が重なる場合はユーザーを優先する
+		if existing, ok := ids[name]; ok && existing.Type == models.MentionTypeUser {
+			continue
+		}
+		ids[name] = mention
+	}
+
+	var mentions []models.HoldingMention
+	seen := make(map[models.HoldingMention]bool)
+	for _, field := range strings.Fields(text) {
+		mention, ok := ids[strings.TrimPrefix(field, "@")]
+		if !ok {
+			return nil, false
+		}
+		if seen[mention] {
+			continue
+		}
+		seen[mention] = true
+		mentions = append(mentions, mention)
+	}
+	if len(mentions) == 0 || len(mentions) > maxHoldingMentions {
+		return nil, false
+	}
+	return mentions, true
+}
+
+// traQMention はtraQのメッセージに埋め込むメンションの形式
+type traQMention struct {
+	Type models.MentionType `json:"type"`
+	Raw  string             `json:"raw"`
+	ID   string             `json:"id"`
+}
+
+// RenderMentions はメンションをtraQのメッセージに埋め込む形式（!{"type":"user","raw":"@name","id":"..."}）にする
+// 既に存在しないユーザー・グループは飛ばし、飛ばしたメンションを返す
+func (s *TraQService) RenderMentions(ctx context.Context, mentions []models.HoldingMention) (string, []models.HoldingMention, error) {
+	names, err := s.mentionNames(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	text, unresolved := renderMentions(names, mentions)
+	return text, unresolved, nil
+}
+
+// renderMentions はIDから名前を引く表を使ってメンションを埋め込む形式にする
+// 表に無いメンションは飛ばして返す
+func renderMentions(names map[models.HoldingMention]string, mentions []models.HoldingMention) (string, []models.HoldingMention) {
+	parts := make([]string, 0, len(mentions))
+	var unresolved []models.HoldingMention
+	for _, mention := range mentions {
+		name, ok := names[models.HoldingMention{Type: mention.Type, TargetID: mention.TargetID}]
+		if !ok {
+			unresolved = append(unresolved, mention)
+			continue
+		}
+		b, _ := json.Marshal(traQMention{Type: mention.Type, Raw: "@" + name, ID: mention.TargetID})
+		parts = append(parts, "!"+string(b))
+	}
+	return strings.Join(parts, " "), unresolved
+}
+
+// Ping はtraQ APIに到達できるかを軽量なAPI呼び出しで確認する
+func (s *TraQService) Ping(ctx context.Context) error {
+	ctx, done := instrumentTraQ(ctx, "GetServerVersion")
+	_, _, err := s.client.PublicApi.GetServerVersion(ctx).Execute()
+	done(err)
+	return err
+}
+
+// errPostRejected はtraQが投稿を4xxで拒否したことを表す（メッセージは作られていない）
+var errPostRejected = errors.New("traq rejected the message")
+
+// PostMessage はメッセージを投稿する。@から始まる名前はメンションに変換される
 func (s *TraQService) PostMessage(ctx context.Context, channelID string, content string) error {
-	_, _, err := s.client.MessageApi.
+	return s.postMessage(ctx, channelID, content, true)
+}
+
+// PostPlainMessage はユーザーが書いた文章をメンションにならないように投稿する
+// @から始まる名前を変換せず、メンションなどの埋め込み（!{...}）も無効にする
+func (s *TraQService) PostPlainMessage(ctx context.Context, channelID string, content string) error {
+	return s.postMessage(ctx, channelID, neutralizeEmbeds(content), false)
+}
+
+// neutralizeEmbeds は"!{"の間にゼロ幅スペースを挟み、traQの埋め込みとして解釈されないようにする
+func neutralizeEmbeds(content string) string {
+	return strings.ReplaceAll(content, "!{", "!\u200b{")
+}
+
+func (s *TraQService) postMessage(ctx context.Context, channelID string, content string, embed bool) error {
+	ctx, done := instrumentTraQ(ctx, "PostMessage", attribute.String("traq.channel_id", channelID))
+	_, res, err := s.client.MessageApi.
 		PostMessage(ctx, channelID).
 		PostMessageRequest(traq.PostMessageRequest{
 			Content: content,
-			Embed:   newBool(true),
+			Embed:   newBool(embed),
 		}).
 		Execute()
+	done(err)
+	if err != nil && res != nil && res.StatusCode >= 400 && res.StatusCode < 500 {
+		return fmt.Errorf("%w: %w", errPostRejected, err)
+	}
 	return err
 }
 
+// instrumentTraQ はtraQ API呼び出しのスパンを開始し、終了時にスパンとメトリクスを記録する関数を返す
+func instrumentTraQ(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	ctx, span := tracer.Start(ctx, "traQ "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	start := time.Now()
+	return ctx, func(err error) {
+		metrics.ObserveTraQRequest(operation, start, err)
+		endSpan(span, err)
+	}
+}
+
 func newBool(b bool) *bool {
 	return &b
 }
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+func TestRenderMentions(t *testing.T) {
+	names := map[models.HoldingMention]string{
+		{Type: models.MentionTypeUser, TargetID: "u1"}:  "alice",
+		{Type: models.MentionTypeGroup, TargetID: "g1"}: "staff",
+	}
+	user := models.HoldingMention{HoldingID: 1, Position: 0, Type: models.MentionTypeUser, TargetID: "u1"}
+	group := models.HoldingMention{HoldingID: 1, Position: 1, Type: models.MentionTypeGroup, TargetID: "g1"}
+	gone := models.HoldingMention{HoldingID: 1, Position: 2, Type: models.MentionTypeUser, TargetID: "u2"}
+	// 同じIDでも種類が違えば別のメンションとして扱う
+	wrongType := models.HoldingMention{HoldingID: 1, Position: 3, Type: models.MentionTypeGroup, TargetID: "u1"}
+
+	tests := []struct {
+		name           string
+		mentions       []models.HoldingMention
+		want           string
+		wantUnresolved []models.HoldingMention
+	}{
+		{
+			name:     "all resolved",
+			mentions: []models.HoldingMention{user, group},
+			want:     `!{"type":"user","raw":"@alice","id":"u1"} !{"type":"group","raw":"@staff","id":"g1"}`,
+		},
+		{
+			name:           "unknown ids are skipped and reported",
+			mentions:       []models.HoldingMention{user, gone, wrongType},
+			want:           `!{"type":"user","raw":"@alice","id":"u1"}`,
+			wantUnresolved: []models.HoldingMention{gone, wrongType},
+		},
+		{
+			name:           "nothing resolved renders empty",
+			mentions:       []models.HoldingMention{gone},
+			want:           "",
+			wantUnresolved: []models.HoldingMention{gone},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unresolved := renderMentions(names, tt.mentions)
+			if got != tt.want {
+				t.Errorf("renderMentions() = %q, want %q", got, tt.want)
+			}
+			if !slices.Equal(unresolved, tt.wantUnresolved) {
+				t.Errorf("renderMentions() unresolved = %v, want %v", unresolved, tt.wantUnresolved)
+			}
+		})
+	}
+}
+
+func TestResolveLegacyMention(t *testing.T) {
+	names := map[models.HoldingMention]string{
+		{Type: models.MentionTypeUser, TargetID: "u1"}:  "alice",
+		{Type: models.MentionTypeGroup, TargetID: "g1"}: "staff",
+		// ユーザーと同じ名前のグループ
+		{Type: models.MentionTypeGroup, TargetID: "g2"}: "alice",
+	}
+	alice := models.HoldingMention{Type: models.MentionTypeUser, TargetID: "u1"}
+	staff := models.HoldingMention{Type: models.MentionTypeGroup, TargetID: "g1"}
+
+	tests := []struct {
+		name   string
+		text   string
+		want   []models.HoldingMention
+		wantOK bool
+	}{
+		{name: "single user", text: "@alice", want: []models.HoldingMention{alice}, wantOK: true},
+		{name: "user and group in order", text: " @staff  @alice ", want: []models.HoldingMention{staff, alice}, wantOK: true},
+		{name: "without at sign", text: "staff", want: []models.HoldingMention{staff}, wantOK: true},
+		{name: "duplicates are merged", text: "@alice @alice", want: []models.HoldingMention{alice}, wantOK: true},
+		{name: "unknown name", text: "@alice @bob", wantOK: false},
+		{name: "blank", text: "  ", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolveLegacyMention(names, tt.text)
+			if ok != tt.wantOK || !slices.Equal(got, tt.want) {
+				t.Errorf("resolveLegacyMention(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// ========================================
+// ゴミ箱（論理削除・復元・完全削除）
+// ========================================
+
+// ParseTrashRetention はゴミ箱に入れたものを完全に削除するまでの期間を解析する（未指定の場合は30日）
+// 0の場合は完全に削除しない
+func ParseTrashRetention(s string) (time.Duration, error) {
+	if s == "" {
+		return defaultTrashRetention, nil
+	}
+	retention, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trash retention: %w", err)
+	}
+	if retention < 0 {
+		return 0, fmt.Errorf("trash retention must not be negative: %s", s)
+	}
+	return retention, nil
+}
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// trashTime はゴミ箱に入れた時刻として記録する値を返す
+// 一緒にゴミ箱に入れたものを同じ値で見分けるため、DATETIME列の精度に揃える
+func trashTime() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// Trash はゴミ箱の中身
+// イベントや開催と一緒にゴミ箱に入れた開催やタスクは含まず、親と一緒に復元される
+// 親が後からゴミ箱に入った開催やタスクも、親を復元するまでは復元できないので含まない
+type Trash struct {
+	Events   []models.Event
+	Holdings []models.Holding
+	Tasks    []models.Task
+}
+
+func (s *TaskService) GetTrash(ctx context.Context) (Trash, error) {
+	trash := Trash{
+		Events:   []models.Event{},
+		Holdings: []models.Holding{},
+		Tasks:    []models.Task{},
+	}
+	err := s.db.SelectContext(ctx, &trash.Events,
+		"SELECT * FROM `events` WHERE `deleted_at` IS NOT NULL ORDER BY `deleted_at` DESC, `id` DESC",
+	)
+	if err != nil {
+		return trash, err
+	}
+	err = s.db.SelectContext(ctx, &trash.Holdings,
+		"SELECT h.* FROM `holdings` h INNER JOIN `events` e ON h.`event_id` = e.`id`"+
+			" WHERE h.`deleted_at` IS NOT NULL AND e.`deleted_at` IS NULL"+
+			" ORDER BY h.`deleted_at` DESC, h.`id` DESC",
+	)
+	if err != nil {
+		return trash, err
+	}
+	if err := s.attachMentions(ctx, trash.Holdings); err != nil {
+		return trash, err
+	}
+	err = s.db.SelectContext(ctx, &trash.Tasks,
+		"SELECT t.* FROM `tasks` t INNER JOIN `holdings` h ON t.`holding_id` = h.`id`"+
+			" WHERE t.`deleted_at` IS NOT NULL AND h.`deleted_at` IS NULL"+
+			" ORDER BY t.`deleted_at` DESC, t.`id` DESC",
+	)
+	if err != nil {
+		return trash, err
+	}
+	return trash, nil
+}
+
+// skipMissedTaskStatus は、ゴミ箱にある間（1つ目の?から2つ目の?まで）にリマインド日時を迎えた
+// pendingのタスクをskippedにするSET句の式（t: tasks）
+// 復元した時点でまとめて送られないようにする（アーカイブの解除と同じ扱い）
+const skipMissedTaskStatus = "t.`status` = IF(t.`status` = 'pending' AND t.`remind_at` >= ? AND t.`remind_at` <= ?, 'skipped', t.`status`)"
+
+// RestoreEvent はイベントを、一緒にゴミ箱に入れた開催とタスクごと復元する
+// ゴミ箱にある間にリマインド日時を迎えたタスクはスキップ扱いにする
+func (s *TaskService) RestoreEvent(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var event models.Event
+	err = tx.GetContext(ctx, &event, "SELECT * FROM `events` WHERE `id` = ? AND `deleted_at` IS NOT NULL FOR UPDATE", id)
+	if err != nil {
+		return notFound(err, "event in trash", id)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `tasks` t INNER JOIN `holdings` h ON t.`holding_id` = h.`id` SET "+skipMissedTaskStatus+", t.`deleted_at` = NULL"+
+			" WHERE h.`event_id` = ? AND t.`deleted_at` = ?",
+		event.DeletedAt, time.Now(), id, event.DeletedAt,
+	)
+	if err != nil {
+		s.logger.Error("failed to restore tasks of event", slog.String("err", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE `holdings` SET `deleted_at` = NULL WHERE `event_id` = ? AND `deleted_at` = ?", id, event.DeletedAt)
+	if err != nil {
+		s.logger.Error("failed to restore holdings of event", slog.String("err", err.Error()))
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE `events` SET `deleted_at` = NULL WHERE `id` = ?", id); err != nil {
+		s.logger.Error("failed to restore event", slog.String("err", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreHolding は開催を、一緒にゴミ箱に入れたタスクごと復元する
+// イベントがゴミ箱にある場合は先にイベントを復元する必要がある
+// ゴミ箱にある間にリマインド日時を迎えたタスクはスキップ扱いにする
+func (s *TaskService) RestoreHolding(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var holding models.Holding
+	err = tx.GetContext(ctx, &holding, "SELECT * FROM `holdings` WHERE `id` = ? AND `deleted_at` IS NOT NULL FOR UPDATE", id)
+	if err != nil {
+		return notFound(err, "holding in trash", id)
+	}
+	var eventDeletedAt *time.Time
+	if err := tx.GetContext(ctx, &eventDeletedAt, "SELECT `deleted_at` FROM `events` WHERE `id` = ? FOR SHARE", holding.EventID); err != nil {
+		return err
+	}
+	if eventDeletedAt != nil {
+		return fmt.Errorf("event %d of holding %d is in the trash: %w", holding.EventID, id, ErrConflict)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `tasks` t SET "+skipMissedTaskStatus+", t.`deleted_at` = NULL WHERE t.`holding_id` = ? AND t.`deleted_at` = ?",
+		holding.DeletedAt, time.Now(), id, holding.DeletedAt,
+	)
+	if err != nil {
+		s.logger.Error("failed to restore tasks of holding", slog.String("err", err.Error()))
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE `holdings` SET `deleted_at` = NULL WHERE `id` = ?", id); err != nil {
+		s.logger.Error("failed to restore holding", slog.String("err", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreTask はタスクを復元する
+// 開催がゴミ箱にある場合は先に開催を復元する必要がある
+// ゴミ箱にある間にリマインド日時を迎えていた場合はスキップ扱いにする
+func (s *TaskService) RestoreTask(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var task models.Task
+	err = tx.GetContext(ctx, &task, "SELECT * FROM `tasks` WHERE `id` = ? AND `deleted_at` IS NOT NULL FOR UPDATE", id)
+	if err != nil {
+		return notFound(err, "task in trash", id)
+	}
+	var holdingDeletedAt *time.Time
+	if err := tx.GetContext(ctx, &holdingDeletedAt, "SELECT `deleted_at` FROM `holdings` WHERE `id` = ? FOR SHARE", task.HoldingID); err != nil {
+		return err
+	}
+	if holdingDeletedAt != nil {
+		return fmt.Errorf("holding %d of task %d is in the trash: %w", task.HoldingID, id, ErrConflict)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE `tasks` t SET "+skipMissedTaskStatus+", t.`deleted_at` = NULL WHERE t.`id` = ?",
+		task.DeletedAt, time.Now(), id,
+	)
+	if err != nil {
+		s.logger.Error("failed to restore task", slog.String("err", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeTrash はbeforeより前にゴミ箱に入れたものを完全に削除し、削除した件数を返す
+// 子の行は外部キーのON DELETE CASCADEで一緒に削除される
+func (s *TaskService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for _, table := range []string{"tasks", "holdings", "events"} {
+		result, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM `%s` WHERE `deleted_at` < ?", table), before)
+		if err != nil {
+			s.logger.Error("failed to purge trash", slog.String("table", table), slog.String("err", err.Error()))
+			return purged, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += n
+	}
+	return purged, nil
+}
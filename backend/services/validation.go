@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pirosiki197/event_reminder/models"
+)
+
+// タスクのリマインド日時として開催日から離せる日数の上限
+const maxTaskOffsetDays = 366
+
+func validateEvent(event models.Event) error {
+	if event.Name == "" {
+		return newValidationError("name", "event name is required")
+	}
+	if event.CatchUpPolicy != "" && !event.CatchUpPolicy.Valid() {
+		return newValidationError("catchUpPolicy", "must be one of all, summary, skip")
+	}
+	if event.BlockedPolicy != "" && !event.BlockedPolicy.Valid() {
+		return newValidationError("blockedPolicy", "must be one of notify, defer")
+	}
+	return nil
+}
+
+func validateHolding(holding models.Holding) error {
+	if holding.Name == "" {
+		return newValidationError("name", "holding name is required")
+	}
+	if holding.Date.IsZero() {
+		return newValidationError("date", "holding date is required")
+	}
+	if holding.EndDate != nil && holding.EndDate.Before(holding.Date) {
+		return newValidationError("endDate", "must be on or after the start date")
+	}
+	if holding.ChannelID == "" {
+		return newValidationError("channelId", "channel id is required")
+	}
+	if holding.Mention == "" && len(holding.Mentions) == 0 {
+		return newValidationError("mentions", "mentions is required")
+	}
+	return validateMentions(holding.Mentions)
+}
+
+func validateTask(task models.Task) error {
+	if task.Name == "" {
+		return newValidationError("name", "task name is required")
+	}
+	if task.Anchor != "" && !task.Anchor.Valid() {
+		return newValidationError("anchor", "must be one of start, end")
+	}
+	if task.DaysBefore < -maxTaskOffsetDays || task.DaysBefore > maxTaskOffsetDays {
+		return newValidationError("daysBefore", fmt.Sprintf("must be between %d and %d", -maxTaskOffsetDays, maxTaskOffsetDays))
+	}
+	if task.OffsetMinutes != nil {
+		if limit := maxTaskOffsetDays * 24 * 60; *task.OffsetMinutes < -limit || *task.OffsetMinutes > limit {
+			return newValidationError("offsetMinutes", fmt.Sprintf("must be between %d and %d", -limit, limit))
+		}
+		if task.RemindTime != nil {
+			return newValidationError("remindTime", "cannot be combined with offsetMinutes")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// lockVersion はトランザクション内で行をロックし、現在の版がexpectedと一致するかを確かめる
+// expectedが0の場合は版を確かめずにロックだけする
+func lockVersion(ctx context.Context, tx *sqlx.Tx, table string, resource string, id int, expected int) error {
+	var version int
+	err := tx.GetContext(ctx, &version,
+		fmt.Sprintf("SELECT `version` FROM `%s` WHERE `id` = ? AND `deleted_at` IS NULL FOR UPDATE", table), id,
+	)
+	if err != nil {
+		return notFound(err, resource, id)
+	}
+	if expected != 0 && version != expected {
+		return fmt.Errorf("%s %d has been modified (version %d, expected %d): %w", resource, id, version, expected, ErrPreconditionFailed)
+	}
+	return nil
+}
@@ -1,10 +1,17 @@
 import type {
+  ChecklistItem,
+  DeadlineWeek,
   Event,
   Holding,
+  HoldingMention,
   HoldingTask,
   HoldingWithEvent,
   HoldingWithTasks,
+  TaskComment,
   TraQChannel,
+  TraQUser,
+  TraQUserGroup,
+  Trash,
 } from '../types';
 
 // API Base URL
@@ -21,8 +28,9 @@ async function fetchJSON<T>(url: string, options?: RequestInit): Promise<T> {
   });
 
   if (!response.ok) {
-    const error = await response.json().catch(() => ({ error: response.statusText }));
-    throw new Error(error.error || `HTTP error! status: ${response.status}`);
+    // エラーは application/problem+json (RFC 7807) で返る
+    const problem = await response.json().catch(() => ({ title: response.statusText }));
+    throw new Error(problem.detail || problem.title || `HTTP error! status: ${response.status}`);
   }
 
   // 204 No Content の場合は空オブジェクトを返す
@@ -33,6 +41,11 @@ async function fetchJSON<T>(url: string, options?: RequestInit): Promise<T> {
   return response.json();
 }
 
+// 取得時の版をIf-Matchに指定し、他の更新と競合した場合は412で失敗させる
+function ifMatch(version?: number): HeadersInit {
+  return version !== undefined ? { 'If-Match': `"${version}"` } : {};
+}
+
 // Events API
 export const eventApi = {
   getAll: async (searchQuery?: string): Promise<Event[]> => {
@@ -65,57 +78,44 @@ export const eventApi = {
       method: 'DELETE',
     });
   },
+
+  archive: async (eventId: string): Promise<Event> => {
+    return fetchJSON<Event>(`${API_BASE_URL}/events/${eventId}/archive`, {
+      method: 'POST',
+    });
+  },
+
+  unarchive: async (eventId: string): Promise<Event> => {
+    return fetchJSON<Event>(`${API_BASE_URL}/events/${eventId}/unarchive`, {
+      method: 'POST',
+    });
+  },
 };
 
 // Holdings API
 export const holdingApi = {
   getAll: async (sourceEventId?: string): Promise<HoldingWithEvent[]> => {
+    // イベント情報は include=event で一緒に取得する
     const url = sourceEventId
-      ? `${API_BASE_URL}/holdings?source_event_id=${sourceEventId}`
-      : `${API_BASE_URL}/holdings`;
-    const holdings = await fetchJSON<Holding[]>(url);
-
-    // イベント情報を付加
-    const holdingsWithEvent: HoldingWithEvent[] = await Promise.all(
-      holdings.map(async (holding) => {
-        try {
-          const event = await fetchJSON<Event>(`${API_BASE_URL}/events/${holding.eventId}`);
-          return {
-            ...holding,
-            event_name: event.name,
-          };
-        } catch (error) {
-          console.error(`Failed to fetch event for holding ${holding.id}:`, error);
-          return {
-            ...holding,
-            event_name: '不明',
-          };
-        }
-      })
-    );
-
-    return holdingsWithEvent;
+      ? `${API_BASE_URL}/holdings?include=event&event_id=${sourceEventId}`
+      : `${API_BASE_URL}/holdings?include=event`;
+    const holdings = await fetchJSON<(Holding & { event?: Event })[]>(url);
+
+    return holdings.map(({ event, ...holding }) => ({
+      ...holding,
+      event_name: event?.name ?? '不明',
+    }));
   },
 
   getById: async (holdingId: string): Promise<HoldingWithTasks> => {
-    const holding = await fetchJSON<Holding>(`${API_BASE_URL}/holdings/${holdingId}`);
-    const tasks = await holdingTaskApi.getByHoldingId(holdingId);
-
-    // イベント情報を取得
-    let eventName = '不明';
-    if (holding.eventId) {
-      try {
-        const event = await fetchJSON<Event>(`${API_BASE_URL}/events/${holding.eventId}`);
-        eventName = event.name;
-      } catch (error) {
-        console.error(`Failed to fetch event for holding ${holdingId}:`, error);
-      }
-    }
+    const { event, tasks, ...holding } = await fetchJSON<
+      Holding & { event?: Event; tasks?: HoldingTask[] }
+    >(`${API_BASE_URL}/holdings/${holdingId}?include=event,tasks`);
 
     return {
       ...holding,
-      event_name: eventName,
-      tasks,
+      event_name: event?.name ?? '不明',
+      tasks: tasks ?? [],
     };
   },
 
@@ -124,7 +124,7 @@ export const holdingApi = {
     eventId: string;
     date: string;
     channelId: string;
-    mention: string;
+    mentions: HoldingMention[];
   }): Promise<Holding> => {
     return fetchJSON<Holding>(`${API_BASE_URL}/holdings`, {
       method: 'POST',
@@ -139,11 +139,13 @@ export const holdingApi = {
       eventId?: string;
       date?: string;
       channelId?: string;
-      mention?: string;
-    }
+      mentions?: HoldingMention[];
+    },
+    version?: number
   ): Promise<Holding> => {
     return fetchJSON<Holding>(`${API_BASE_URL}/holdings/${holdingId}`, {
       method: 'PATCH',
+      headers: ifMatch(version),
       body: JSON.stringify(updates),
     });
   },
@@ -153,6 +155,23 @@ export const holdingApi = {
       method: 'DELETE',
     });
   },
+
+  // タスクごと複製する（date以外は省略すると複製元の値を引き継ぐ）
+  clone: async (
+    holdingId: string,
+    overrides: {
+      date: string;
+      name?: string;
+      eventId?: string;
+      channelId?: string;
+      mentions?: HoldingMention[];
+    }
+  ): Promise<Holding> => {
+    return fetchJSON<Holding>(`${API_BASE_URL}/holdings/${holdingId}/clone`, {
+      method: 'POST',
+      body: JSON.stringify(overrides),
+    });
+  },
 };
 
 // HoldingTasks API
@@ -183,10 +202,13 @@ export const holdingTaskApi = {
       name?: string;
       daysBefore?: number;
       description?: string;
-    }
+      completed?: boolean;
+    },
+    version?: number
   ): Promise<HoldingTask> => {
     return fetchJSON<HoldingTask>(`${API_BASE_URL}/holding-tasks/${taskId}`, {
       method: 'PATCH',
+      headers: ifMatch(version),
       body: JSON.stringify(updates),
     });
   },
@@ -196,6 +218,122 @@ export const holdingTaskApi = {
       method: 'DELETE',
     });
   },
+
+  // 作成・更新・削除と日数のずらしを1つのトランザクションでまとめて行う
+  batch: async (
+    holdingId: string,
+    operations: {
+      create?: { name: string; daysBefore: number; description: string }[];
+      update?: { id: string; name?: string; daysBefore?: number; description?: string; completed?: boolean }[];
+      delete?: string[];
+      shiftDays?: number;
+    }
+  ): Promise<{ created: string[]; tasks: HoldingTask[] }> => {
+    return fetchJSON<{ created: string[]; tasks: HoldingTask[] }>(`${API_BASE_URL}/holdings/${holdingId}/tasks`, {
+      method: 'PATCH',
+      body: JSON.stringify(operations),
+    });
+  },
+};
+
+// Checklist API
+export const checklistApi = {
+  getByTaskId: async (taskId: string): Promise<ChecklistItem[]> => {
+    return fetchJSON<ChecklistItem[]>(`${API_BASE_URL}/holding-tasks/${taskId}/checklist`);
+  },
+
+  create: async (taskId: string, text: string): Promise<ChecklistItem> => {
+    return fetchJSON<ChecklistItem>(`${API_BASE_URL}/holding-tasks/${taskId}/checklist`, {
+      method: 'POST',
+      body: JSON.stringify({ text }),
+    });
+  },
+
+  update: async (
+    itemId: string,
+    updates: {
+      text?: string;
+      done?: boolean;
+      position?: number;
+    }
+  ): Promise<ChecklistItem> => {
+    return fetchJSON<ChecklistItem>(`${API_BASE_URL}/checklist-items/${itemId}`, {
+      method: 'PATCH',
+      body: JSON.stringify(updates),
+    });
+  },
+
+  delete: async (itemId: string): Promise<void> => {
+    await fetchJSON<void>(`${API_BASE_URL}/checklist-items/${itemId}`, {
+      method: 'DELETE',
+    });
+  },
+};
+
+// Comments API
+export const commentApi = {
+  getByTaskId: async (taskId: string): Promise<TaskComment[]> => {
+    return fetchJSON<TaskComment[]>(`${API_BASE_URL}/holding-tasks/${taskId}/comments`);
+  },
+
+  create: async (
+    taskId: string,
+    comment: { body: string; notify?: boolean }
+  ): Promise<TaskComment> => {
+    return fetchJSON<TaskComment>(`${API_BASE_URL}/holding-tasks/${taskId}/comments`, {
+      method: 'POST',
+      body: JSON.stringify(comment),
+    });
+  },
+
+  update: async (commentId: string, body: string): Promise<TaskComment> => {
+    return fetchJSON<TaskComment>(`${API_BASE_URL}/comments/${commentId}`, {
+      method: 'PATCH',
+      body: JSON.stringify({ body }),
+    });
+  },
+
+  delete: async (commentId: string): Promise<void> => {
+    await fetchJSON<void>(`${API_BASE_URL}/comments/${commentId}`, {
+      method: 'DELETE',
+    });
+  },
+};
+
+
+export const deadlineApi = {
+  getByWeek: async (params?: { from?: string; to?: string }): Promise<DeadlineWeek[]> => {
+    const query = new URLSearchParams();
+    if (params?.from) query.set('from', params.from);
+    if (params?.to) query.set('to', params.to);
+    const qs = query.toString();
+    return fetchJSON<DeadlineWeek[]>(`${API_BASE_URL}/deadlines${qs ? `?${qs}` : ''}`);
+  },
+};
+
+// Trash API
+export const trashApi = {
+  get: async (): Promise<Trash> => {
+    return fetchJSON<Trash>(`${API_BASE_URL}/trash`);
+  },
+
+  restoreEvent: async (eventId: string): Promise<void> => {
+    await fetchJSON<void>(`${API_BASE_URL}/events/${eventId}/restore`, {
+      method: 'POST',
+    });
+  },
+
+  restoreHolding: async (holdingId: string): Promise<void> => {
+    await fetchJSON<void>(`${API_BASE_URL}/holdings/${holdingId}/restore`, {
+      method: 'POST',
+    });
+  },
+
+  restoreTask: async (taskId: string): Promise<void> => {
+    await fetchJSON<void>(`${API_BASE_URL}/holding-tasks/${taskId}/restore`, {
+      method: 'POST',
+    });
+  },
 };
 
 // traQ API
@@ -203,4 +341,12 @@ export const traqApi = {
   getChannels: async (): Promise<TraQChannel[]> => {
     return fetchJSON<TraQChannel[]>(`${API_BASE_URL}/channels`);
   },
+
+  getUsers: async (): Promise<TraQUser[]> => {
+    return fetchJSON<TraQUser[]>(`${API_BASE_URL}/users`);
+  },
+
+  getUserGroups: async (): Promise<TraQUserGroup[]> => {
+    return fetchJSON<TraQUserGroup[]>(`${API_BASE_URL}/user-groups`);
+  },
 };
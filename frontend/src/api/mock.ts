@@ -74,7 +74,8 @@ const holdings: Holding[] = [
     name: '第21回 ゲーム展示イベント',
     date: '2026-04-18',
     channelId: 'C01234567',
-    mention: '@運営',
+    mentions: [{ type: 'group', id: 'G01234567' }],
+    mention: '',
     eventId: '1',
   },
   {
@@ -82,7 +83,8 @@ const holdings: Holding[] = [
     name: '第20回 ゲーム展示イベント',
     date: '2025-12-20',
     channelId: 'C01234567',
-    mention: '@運営',
+    mentions: [{ type: 'group', id: 'G01234567' }],
+    mention: '',
     eventId: '1',
   },
   {
@@ -90,7 +92,11 @@ const holdings: Holding[] = [
     name: '2026年夏合宿',
     date: '2026-08-15',
     channelId: 'C89012345',
-    mention: '@here',
+    mentions: [
+      { type: 'user', id: 'U01234567' },
+      { type: 'user', id: 'U89012345' },
+    ],
+    mention: '',
     eventId: '2',
   },
 ];
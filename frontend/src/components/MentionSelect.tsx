@@ -0,0 +1,83 @@
+import type React from 'react';
+import { useMentionName } from '../hooks/useMentionName';
+import { useAppStore } from '../store';
+import type { HoldingMention } from '../types';
+import { SearchableSelect } from './SearchableSelect';
+
+interface MentionSelectProps {
+  label?: string;
+  value: HoldingMention[];
+  onChange: (value: HoldingMention[]) => void;
+  error?: string;
+}
+
+// 選択肢の値は「種類:ID」の形にする
+const toOptionValue = (mention: HoldingMention) => `${mention.type}:${mention.id}`;
+
+/**
+ * メンションするtraQのユーザー・グループを複数選ぶ入力欄
+ * 候補はストアのtraQUsers / traQUserGroupsから作るため、先にfetchTraQUsersを呼んでおく
+ */
+export const MentionSelect: React.FC<MentionSelectProps> = ({ label, value, onChange, error }) => {
+  const { traQUsers, traQUserGroups } = useAppStore();
+  const mentionName = useMentionName();
+
+  const selected = new Set(value.map(toOptionValue));
+  const options = [
+    ...traQUserGroups.map((g) => ({
+      value: toOptionValue({ type: 'group', id: g.id }),
+      label: `@${g.name}（グループ）`,
+    })),
+    ...traQUsers
+      .filter((u) => !u.bot)
+      .map((u) => ({
+        value: toOptionValue({ type: 'user', id: u.id }),
+        label: `@${u.name}`,
+      })),
+  ].filter((option) => !selected.has(option.value));
+
+  const handleAdd = (optionValue: string) => {
+    const [type, id] = optionValue.split(':');
+    if (type !== 'user' && type !== 'group') return;
+    onChange([...value, { type, id }]);
+  };
+
+  const handleRemove = (mention: HoldingMention) => {
+    onChange(value.filter((m) => toOptionValue(m) !== toOptionValue(mention)));
+  };
+
+  return (
+    <div className="w-full">
+      {label && <label className="block text-sm font-medium text-gray-700 mb-1">{label}</label>}
+
+      {value.length > 0 && (
+        <div className="flex flex-wrap gap-2 mb-2">
+          {value.map((mention) => (
+            <span
+              key={toOptionValue(mention)}
+              className="inline-flex items-center gap-1 px-2.5 py-0.5 rounded-full text-sm bg-blue-100 text-blue-800"
+            >
+              {mentionName(mention)}
+              <button
+                type="button"
+                onClick={() => handleRemove(mention)}
+                className="text-blue-600 hover:text-blue-900"
+                aria-label="メンション先から外す"
+              >
+                ×
+              </button>
+            </span>
+          ))}
+        </div>
+      )}
+
+      <SearchableSelect
+        value=""
+        onChange={handleAdd}
+        options={options}
+        error={error}
+        placeholder="ユーザー・グループを検索..."
+      />
+    </div>
+  );
+};
@@ -0,0 +1,33 @@
+import { useAppStore } from '../store';
+import type { Holding, HoldingMention } from '../types';
+
+/**
+ * メンション先のユーザー・グループの名前を引く関数を返すカスタムフック
+ * @returns メンションを「@名前」にする関数（見つからない場合はIDをそのまま返す）
+ */
+export const useMentionName = (): ((mention: HoldingMention) => string) => {
+  const { traQUsers, traQUserGroups } = useAppStore();
+
+  return (mention) => {
+    const name =
+      mention.type === 'user'
+        ? traQUsers.find((u) => u.id === mention.id)?.name
+        : traQUserGroups.find((g) => g.id === mention.id)?.name;
+    return name ? `@${name}` : mention.id;
+  };
+};
+
+/**
+ * 開催の通知先を表示用の文字列にする関数を返すカスタムフック
+ * @returns ユーザー・グループを指定していない開催は旧形式のメンションをそのまま返す関数
+ */
+export const useMentionText = (): ((holding: Holding) => string) => {
+  const mentionName = useMentionName();
+
+  return (holding) => {
+    if (!holding.mentions || holding.mentions.length === 0) {
+      return holding.mention;
+    }
+    return holding.mentions.map(mentionName).join(' ');
+  };
+};
@@ -4,20 +4,24 @@ import { useNavigate, useParams } from 'react-router-dom';
 import { Button } from '../components/Button';
 import { LoadingSpinner } from '../components/LoadingSpinner';
 import { useChannelName } from '../hooks/useChannelName';
+import { useMentionText } from '../hooks/useMentionName';
 import { useAppStore } from '../store';
 
 export const HoldingDetail: React.FC = () => {
   const { holdingId } = useParams<{ holdingId: string }>();
   const navigate = useNavigate();
-  const { currentHolding, isLoading, fetchHoldingById, fetchTraQChannels } = useAppStore();
+  const { currentHolding, isLoading, fetchHoldingById, fetchTraQChannels, fetchTraQUsers } =
+    useAppStore();
   const channelName = useChannelName(currentHolding?.channelId || '');
+  const mentionText = useMentionText();
 
   useEffect(() => {
     if (holdingId) {
       fetchHoldingById(holdingId);
     }
     fetchTraQChannels();
-  }, [holdingId, fetchHoldingById, fetchTraQChannels]);
+    fetchTraQUsers();
+  }, [holdingId, fetchHoldingById, fetchTraQChannels, fetchTraQUsers]);
 
   if (!holdingId) {
     return <div>開催IDが指定されていません</div>;
@@ -118,7 +122,7 @@ export const HoldingDetail: React.FC = () => {
             </div>
             <div className="flex items-start">
               <span className="font-medium text-gray-700 w-32">通知先:</span>
-              <span className="text-gray-900">{currentHolding.mention}</span>
+              <span className="text-gray-900">{mentionText(currentHolding)}</span>
             </div>
             <div className="flex items-start">
               <span className="font-medium text-gray-700 w-32">通知先チャンネル:</span>
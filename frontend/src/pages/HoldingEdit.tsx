@@ -6,10 +6,11 @@ import 'react-datepicker/dist/react-datepicker.css';
 import { Button } from '../components/Button';
 import { Input, Textarea } from '../components/Form';
 import { LoadingSpinner } from '../components/LoadingSpinner';
+import { MentionSelect } from '../components/MentionSelect';
 import { Modal } from '../components/Modal';
 import { SearchableSelect } from '../components/SearchableSelect';
 import { useAppStore } from '../store';
-import type { HoldingTask } from '../types';
+import type { HoldingMention, HoldingTask } from '../types';
 
 export const HoldingEdit: React.FC = () => {
   const { holdingId } = useParams<{ holdingId: string }>();
@@ -20,6 +21,7 @@ export const HoldingEdit: React.FC = () => {
     isLoading,
     fetchHoldingById,
     fetchTraQChannels,
+    fetchTraQUsers,
     updateHolding,
     deleteHolding,
     createHoldingTask,
@@ -32,7 +34,7 @@ export const HoldingEdit: React.FC = () => {
     name: '',
     date: new Date(),
     channelId: '',
-    mention: '',
+    mentions: [] as HoldingMention[],
   });
   const [isTaskModalOpen, setIsTaskModalOpen] = useState(false);
   const [editingTask, setEditingTask] = useState<HoldingTask | null>(null);
@@ -47,7 +49,8 @@ export const HoldingEdit: React.FC = () => {
       fetchHoldingById(holdingId);
     }
     fetchTraQChannels();
-  }, [holdingId, fetchHoldingById, fetchTraQChannels]);
+    fetchTraQUsers();
+  }, [holdingId, fetchHoldingById, fetchTraQChannels, fetchTraQUsers]);
 
   useEffect(() => {
     if (currentHolding) {
@@ -55,7 +58,7 @@ export const HoldingEdit: React.FC = () => {
         name: currentHolding.name,
         date: new Date(currentHolding.date),
         channelId: currentHolding.channelId,
-        mention: currentHolding.mention,
+        mentions: currentHolding.mentions ?? [],
       });
     }
   }, [currentHolding]);
@@ -70,7 +73,7 @@ export const HoldingEdit: React.FC = () => {
         name: currentHolding.name,
         date: new Date(currentHolding.date),
         channelId: currentHolding.channelId,
-        mention: currentHolding.mention,
+        mentions: currentHolding.mentions ?? [],
       });
       setIsEditInfoModalOpen(true);
     }
@@ -87,8 +90,10 @@ export const HoldingEdit: React.FC = () => {
       return;
     }
 
-    if (holdingFormData.mention.trim() === '') {
-      alert('メンション先を入力してください');
+    // 旧形式のメンションしか無い開催は、選び直すまでそのメンションを使い続ける
+    const hasLegacyMention = !!currentHolding?.mention;
+    if (holdingFormData.mentions.length === 0 && !hasLegacyMention) {
+      alert('メンション先を選択してください');
       return;
     }
 
@@ -98,7 +103,7 @@ export const HoldingEdit: React.FC = () => {
         eventId: currentHolding.eventId || '',
         date: holdingFormData.date.toISOString().split('T')[0],
         channelId: holdingFormData.channelId,
-        mention: holdingFormData.mention,
+        ...(holdingFormData.mentions.length > 0 && { mentions: holdingFormData.mentions }),
       });
     }
     setIsEditInfoModalOpen(false);
@@ -319,12 +324,16 @@ export const HoldingEdit: React.FC = () => {
             placeholder="チャンネルを検索..."
           />
 
-          <Input
+          <MentionSelect
             label="メンション先"
-            value={holdingFormData.mention}
-            onChange={(e) => setHoldingFormData({ ...holdingFormData, mention: e.target.value })}
-            placeholder="例: @運営, @here"
+            value={holdingFormData.mentions}
+            onChange={(mentions) => setHoldingFormData({ ...holdingFormData, mentions })}
           />
+          {holdingFormData.mentions.length === 0 && currentHolding?.mention && (
+            <p className="text-sm text-gray-600">
+              旧形式のメンション「{currentHolding.mention}」を使っています。ユーザー・グループを選ぶと置き換わります
+            </p>
+          )}
 
           <div className="flex gap-3 justify-end">
             <Button variant="secondary" onClick={() => setIsEditInfoModalOpen(false)}>
@@ -6,8 +6,10 @@ import 'react-datepicker/dist/react-datepicker.css';
 import { Button } from '../components/Button';
 import { Input } from '../components/Form';
 import { LoadingSpinner } from '../components/LoadingSpinner';
+import { MentionSelect } from '../components/MentionSelect';
 import { SearchableSelect } from '../components/SearchableSelect';
 import { useAppStore } from '../store';
+import type { HoldingMention } from '../types';
 
 export const HoldingForm: React.FC = () => {
   const { eventId, holdingId } = useParams<{ eventId: string; holdingId?: string }>();
@@ -20,6 +22,7 @@ export const HoldingForm: React.FC = () => {
     isLoading,
     fetchEvents,
     fetchTraQChannels,
+    fetchTraQUsers,
     fetchHoldingsByEventId,
     fetchHoldingById,
     createHolding,
@@ -31,7 +34,7 @@ export const HoldingForm: React.FC = () => {
     eventId: eventId || '',
     holdingDate: new Date(),
     channelId: '',
-    mention: '',
+    mentions: [] as HoldingMention[],
   });
 
   const [errors, setErrors] = useState<Record<string, string>>({});
@@ -39,12 +42,13 @@ export const HoldingForm: React.FC = () => {
   useEffect(() => {
     fetchEvents();
     fetchTraQChannels();
+    fetchTraQUsers();
     if (eventId) {
       fetchHoldingsByEventId(eventId);
       // eventIdをformDataに設定
       setFormData((prev) => ({ ...prev, eventId }));
     }
-  }, [fetchEvents, fetchTraQChannels, fetchHoldingsByEventId, eventId]);
+  }, [fetchEvents, fetchTraQChannels, fetchTraQUsers, fetchHoldingsByEventId, eventId]);
 
   // 同じイベントの最新開催からデフォルト値を設定
   useEffect(() => {
@@ -59,7 +63,7 @@ export const HoldingForm: React.FC = () => {
         setFormData((prev) => ({
           ...prev,
           channelId: latestHolding.channelId,
-          mention: latestHolding.mention,
+          mentions: latestHolding.mentions ?? [],
         }));
       }
     }
@@ -80,7 +84,7 @@ export const HoldingForm: React.FC = () => {
         eventId: currentHolding.eventId || eventId || '',
         holdingDate: new Date(currentHolding.date),
         channelId: currentHolding.channelId,
-        mention: currentHolding.mention,
+        mentions: currentHolding.mentions ?? [],
       });
     }
   }, [holdingId, currentHolding, eventId]);
@@ -100,8 +104,8 @@ export const HoldingForm: React.FC = () => {
       newErrors.slack_channel_id = '通知先チャンネルを選択してください';
     }
 
-    if (!formData.mention.trim()) {
-      newErrors.slack_mention = 'メンション先は必須です';
+    if (formData.mentions.length === 0) {
+      newErrors.slack_mention = 'メンション先を選択してください';
     }
 
     setErrors(newErrors);
@@ -218,11 +222,10 @@ export const HoldingForm: React.FC = () => {
             />
 
             {/* メンション先 */}
-            <Input
+            <MentionSelect
               label="メンション先 *"
-              placeholder="例: @運営, @here"
-              value={formData.mention}
-              onChange={(e) => setFormData({ ...formData, mention: e.target.value })}
+              value={formData.mentions}
+              onChange={(mentions) => setFormData({ ...formData, mentions })}
               error={errors.slack_mention}
             />
 
@@ -4,13 +4,21 @@ import { useNavigate, useParams } from 'react-router-dom';
 import { Button } from '../components/Button';
 import { Card } from '../components/Card';
 import { LoadingSpinner } from '../components/LoadingSpinner';
+import { useMentionText } from '../hooks/useMentionName';
 import { useAppStore } from '../store';
 
 export const HoldingList: React.FC = () => {
   const { eventId } = useParams<{ eventId: string }>();
   const navigate = useNavigate();
-  const { currentEvent, holdings, isLoading, fetchEventById, fetchHoldingsByEventId } =
-    useAppStore();
+  const {
+    currentEvent,
+    holdings,
+    isLoading,
+    fetchEventById,
+    fetchHoldingsByEventId,
+    fetchTraQUsers,
+  } = useAppStore();
+  const mentionText = useMentionText();
 
   const [filter, setFilter] = useState<'upcoming' | 'past'>('upcoming');
 
@@ -19,7 +27,8 @@ export const HoldingList: React.FC = () => {
       fetchEventById(eventId);
       fetchHoldingsByEventId(eventId);
     }
-  }, [eventId, fetchEventById, fetchHoldingsByEventId]);
+    fetchTraQUsers();
+  }, [eventId, fetchEventById, fetchHoldingsByEventId, fetchTraQUsers]);
 
   if (!eventId) {
     return <div>イベントIDが指定されていません</div>;
@@ -133,7 +142,7 @@ export const HoldingList: React.FC = () => {
                           })}
                         </span>
                       </p>
-                      <p>💬 通知先: {holding.mention}</p>
+                      <p>💬 通知先: {mentionText(holding)}</p>
                     </div>
                   </div>
                   <svg
@@ -1,6 +1,14 @@
 import { create } from 'zustand';
 import { eventApi, holdingApi, holdingTaskApi, traqApi } from '../api/api';
-import type { Event, HoldingWithEvent, HoldingWithTasks, TraQChannel } from '../types';
+import type {
+  Event,
+  HoldingMention,
+  HoldingWithEvent,
+  HoldingWithTasks,
+  TraQChannel,
+  TraQUser,
+  TraQUserGroup,
+} from '../types';
 
 interface AppState {
   // データ
@@ -9,6 +17,8 @@ interface AppState {
   holdings: HoldingWithEvent[];
   currentHolding: HoldingWithTasks | null;
   traQChannels: TraQChannel[];
+  traQUsers: TraQUser[];
+  traQUserGroups: TraQUserGroup[];
 
   // ローディング状態
   isLoading: boolean;
@@ -30,7 +40,7 @@ interface AppState {
     eventId: string;
     date: string;
     channelId: string;
-    mention: string;
+    mentions: HoldingMention[];
   }) => Promise<void>;
   updateHolding: (
     holdingId: string,
@@ -39,7 +49,7 @@ interface AppState {
       eventId?: string;
       date?: string;
       channelId?: string;
-      mention?: string;
+      mentions?: HoldingMention[];
     }
   ) => Promise<void>;
   deleteHolding: (holdingId: string) => Promise<void>;
@@ -63,6 +73,8 @@ interface AppState {
 
   // アクション: Slack
   fetchTraQChannels: () => Promise<void>;
+  // メンション先の候補（ユーザーとユーザーグループ）をまとめて取得
+  fetchTraQUsers: () => Promise<void>;
 }
 
 export const useAppStore = create<AppState>((set, get) => ({
@@ -72,6 +84,8 @@ export const useAppStore = create<AppState>((set, get) => ({
   holdings: [],
   currentHolding: null,
   traQChannels: [],
+  traQUsers: [],
+  traQUserGroups: [],
   isLoading: false,
 
   // イベント関連 (旧: テンプレート)
@@ -305,4 +319,15 @@ export const useAppStore = create<AppState>((set, get) => ({
       set({ isLoading: false });
     }
   },
+
+  fetchTraQUsers: async () => {
+    set({ isLoading: true });
+    try {
+      const [users, groups] = await Promise.all([traqApi.getUsers(), traqApi.getUserGroups()]);
+      set({ traQUsers: users, traQUserGroups: groups, isLoading: false });
+    } catch (error) {
+      console.error('Failed to fetch traQ users:', error);
+      set({ isLoading: false });
+    }
+  },
 }));
@@ -2,6 +2,8 @@
 export interface Event {
   id: string;
   name: string;
+  archivedAt?: string | null; // アーカイブしていなければnull
+  version?: number; // 楽観的排他制御のための版
 }
 
 // 開催 (旧: Event)
@@ -9,9 +11,21 @@ export interface Holding {
   id: string;
   name: string;
   date: string; // ISO date string (YYYY-MM-DD)
+  startTime?: string; // HH:MM
+  endDate?: string; // 複数日にわたる開催の最終日 (YYYY-MM-DD)
   channelId: string;
-  mention: string;
+  channelStatus?: 'ok' | 'archived' | 'not_found'; // 定期的な確認で分かった通知先チャンネルの状態
+  channelCheckedAt?: string; // ISO datetime string
+  mentions?: HoldingMention[]; // メンションするtraQのユーザー・グループ
+  mention: string; // 旧形式の自由記述のメンション（読み取り専用。mentionsが空の場合のみ使われる）
   eventId?: string; // コピー元のイベントID
+  version?: number; // 楽観的排他制御のための版
+}
+
+// 開催のメンション先
+export interface HoldingMention {
+  type: 'user' | 'group';
+  id: string; // traQのユーザー・ユーザーグループのUUID
 }
 
 // 開催タスク (新規)
@@ -19,8 +33,67 @@ export interface HoldingTask {
   id: string;
   holdingId: string;
   name: string;
-  daysBefore: number;
+  anchor?: 'start' | 'end'; // リマインド日時の基準（開催の初日 / 最終日）
+  daysBefore: number; // 負の場合は基準日の後
+  offsetMinutes?: number; // 開始日時からの分単位のオフセット（負の場合は開始前）
+  remindTime?: string; // HH:MM
+  remindAt?: string; // ISO datetime string
   description: string;
+  status?: 'pending' | 'sending' | 'sent' | 'failed' | 'skipped';
+  completed?: boolean;
+  completedAt?: string;
+  dependsOn?: string[]; // 前提タスクのID
+  version?: number; // 楽観的排他制御のための版
+}
+
+// タスクのチェックリストの項目
+export interface ChecklistItem {
+  id: string;
+  taskId: string;
+  position: number; // 0始まりの並び順
+  text: string;
+  done: boolean;
+  doneAt?: string;
+}
+
+// タスクへのコメント
+export interface TaskComment {
+  id: string;
+  taskId: string;
+  author: string; // traQのユーザー名
+  body: string;
+  createdAt: string;
+  updatedAt: string;
+  edited: boolean;
+  notified?: boolean; // チャンネルに投稿できたか（notifyを指定した場合のみ）
+}
+
+// タスクの締め切り
+export interface Deadline extends HoldingTask {
+  holdingName: string;
+  holdingDate: string; // YYYY-MM-DD
+  holdingEndDate?: string; // YYYY-MM-DD
+  channelId: string;
+  eventId: string;
+  eventName: string;
+  remindDate: string; // YYYY-MM-DD
+  daysRemaining: number;
+  reminded: boolean;
+  overdue: boolean;
+}
+
+// 週（月曜始まり）ごとの締め切り
+export interface DeadlineWeek {
+  weekStart: string; // YYYY-MM-DD
+  weekEnd: string; // YYYY-MM-DD
+  deadlines: Deadline[];
+}
+
+// ゴミ箱の中身（deletedAtはゴミ箱に入れた時刻）
+export interface Trash {
+  events: (Event & { deletedAt: string })[];
+  holdings: (Holding & { deletedAt: string })[];
+  tasks: (HoldingTask & { deletedAt: string })[];
 }
 
 // traQチャンネル (モック用)
@@ -29,6 +102,21 @@ export interface TraQChannel {
   name: string;
 }
 
+// traQユーザー
+export interface TraQUser {
+  id: string;
+  name: string;
+  displayName: string;
+  bot: boolean;
+}
+
+// traQユーザーグループ
+export interface TraQUserGroup {
+  id: string;
+  name: string;
+  description: string;
+}
+
 // 開催とイベント情報を結合した型
 export interface HoldingWithEvent extends Holding {
   event_name: string;